@@ -0,0 +1,71 @@
+// spool is a tool to inspect and drain lazyevent spool directories.
+//
+// Usage:
+//
+//	spool -dir path/to/spool          print spooled events and spool stats
+//	spool -dir path/to/spool -drain   print spooled events and remove them from spool
+//	spool -dir path/to/spool -json    print spooled events as JSON, one per line (can be used with -drain)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	logger "github.com/lazybark/lazyevent/v4"
+)
+
+func main() {
+	dir := flag.String("dir", "", "spool directory")
+	drain := flag.Bool("drain", false, "remove printed events from spool")
+	jsonOut := flag.Bool("json", false, "print events as JSON")
+	flag.Parse()
+
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err := os.Stat(*dir); err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := logger.NewSpool(*dir, 0, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	printEvent := func(e logger.Event, timeFormat string) error {
+		if timeFormat == "" {
+			timeFormat = time.UnixDate
+		}
+		if !*jsonOut {
+			fmt.Print(logger.FormatOutput(e, timeFormat))
+			return nil
+		}
+		js, err := logger.FormatJSON(e, timeFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(js))
+
+		return nil
+	}
+
+	st := s.Stats()
+	if *drain {
+		n, err := s.Replay(printEvent)
+		fmt.Fprintf(os.Stderr, "drained %d events from %d segments\n", n, st.Segments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := s.Inspect(printEvent); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "%d segments, %d bytes waiting\n", st.Segments, st.Bytes)
+}
@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrFileBufferFull is returned by BufferedFile.Write in case underlying file can not be written
+// and buffer has no room for the record
+var ErrFileBufferFull = errors.New("file buffer is full")
+
+// IFlusher is implemented by files and loggers that keep data in memory before writing it.
+// LogProcessor calls Flush on such loggers in Flush() and Close().
+type IFlusher interface {
+	Flush() error
+}
+
+// FlushPolicy determines when BufferedFile writes buffered data to the file.
+// Data is written as soon as any of conditions is met, zero values are not checked.
+// Zero policy means no buffering at all.
+type FlushPolicy struct {
+	//MaxBytes is the buffer size: data is flushed when buffer has that much bytes (64KB if zero).
+	//While file can not be written, buffer keeps up to 4 * MaxBytes of records
+	MaxBytes int
+
+	//Interval makes buffer flush every T even if nothing is being logged
+	Interval time.Duration
+
+	//Level makes buffer flush right after event of this level or higher is written
+	Level Level
+
+	//Sync makes file sync to disk (fsync) after each flush
+	Sync bool
+}
+
+// buffered returns true in case policy requires buffering
+func (p FlushPolicy) buffered() bool {
+	return p != FlushPolicy{}
+}
+
+const (
+	defaultFileBuffer = 64 << 10
+	bufferedFileLimit = 4
+)
+
+// BufferedFile is an IFile that keeps written data in memory and writes it to the
+// underlying file according to FlushPolicy. It trades durability for fewer syscalls.
+//
+// Every Write is treated as a record: records are never split between writes to the underlying
+// file, so each of them (e.g. with file lock taken) contains whole records only.
+//
+// Records stay in buffer until they are written: failed flushes are retried by next ones and their
+// errors are returned by Flush and Close.
+type BufferedFile struct {
+	mu      sync.Mutex
+	f       IFile
+	buf     []byte
+	size    int
+	policy  FlushPolicy
+	lastErr error
+	stop    chan struct{}
+}
+
+// NewBufferedFile returns BufferedFile that writes into f according to p.
+// In case p.Interval > 0, separate routine flushes the buffer until Close is called.
+func NewBufferedFile(f IFile, p FlushPolicy) *BufferedFile {
+	size := p.MaxBytes
+	if size <= 0 {
+		size = defaultFileBuffer
+	}
+	bf := &BufferedFile{f: f, buf: make([]byte, 0, size), size: size, policy: p}
+
+	if p.Interval > 0 {
+		bf.stop = make(chan struct{})
+		go bf.flushEvery(p.Interval, bf.stop)
+	}
+
+	return bf
+}
+
+func (bf *BufferedFile) flushEvery(d time.Duration, stop chan struct{}) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			bf.mu.Lock()
+			bf.keepErr(bf.flush())
+			bf.mu.Unlock()
+		}
+	}
+}
+
+// Write puts b into buffer. Error is returned only in case b was not buffered: flush errors are kept
+// until Flush or Close, as records stay in buffer.
+func (bf *BufferedFile) Write(b []byte) (int, error) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	//Record that does not fit is not split: buffered records are written first
+	if len(bf.buf) > 0 && len(bf.buf)+len(b) > bf.size {
+		err := bf.flush()
+		if err != nil && len(bf.buf)+len(b) > bf.size*bufferedFileLimit {
+			return 0, fmt.Errorf("[BufferedFile][Write] %w: %w", ErrFileBufferFull, err)
+		}
+		bf.keepErr(err)
+	}
+	bf.buf = append(bf.buf, b...)
+	if len(bf.buf) >= bf.size {
+		bf.keepErr(bf.flush())
+	}
+
+	return len(b), nil
+}
+
+// WriteString puts s into buffer, see Write
+func (bf *BufferedFile) WriteString(s string) (int, error) {
+	return bf.Write([]byte(s))
+}
+
+// Written tells buffer that event of level l was written, so it should be flushed in case
+// l >= FlushPolicy.Level
+func (bf *BufferedFile) Written(l Level) error {
+	if bf.policy.Level <= el_start || l < bf.policy.Level {
+		return nil
+	}
+
+	return bf.Flush()
+}
+
+// Flush writes buffered data to the file and syncs it in case FlushPolicy.Sync is true.
+// Error of previous failed flush (if any) is returned here even if data is written now.
+func (bf *BufferedFile) Flush() error {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	return bf.flushAll()
+}
+
+// flushAll flushes the buffer and returns its error or error of previous failed flush
+func (bf *BufferedFile) flushAll() error {
+	err := bf.flush()
+	if prev := bf.takeErr(); err == nil {
+		err = prev
+	}
+
+	return err
+}
+
+// flush writes buffered records with a single write
+func (bf *BufferedFile) flush() error {
+	if len(bf.buf) > 0 {
+		n, err := bf.f.Write(bf.buf)
+		if err == nil && n < len(bf.buf) {
+			err = io.ErrShortWrite
+		}
+		//Data that was not written stays in buffer for the next attempt
+		if n > 0 {
+			bf.buf = bf.buf[:copy(bf.buf, bf.buf[n:])]
+		}
+		if err != nil {
+			return fmt.Errorf("[BufferedFile][Flush] %w", err)
+		}
+	}
+	if !bf.policy.Sync {
+		return nil
+	}
+	if s, ok := bf.f.(interface{ Sync() error }); ok {
+		if err := s.Sync(); err != nil {
+			return fmt.Errorf("[BufferedFile][Flush] error syncing file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// keepErr saves error of flush to be returned by Flush or Close
+func (bf *BufferedFile) keepErr(err error) {
+	if err != nil {
+		bf.lastErr = err
+	}
+}
+
+func (bf *BufferedFile) takeErr() error {
+	err := bf.lastErr
+	bf.lastErr = nil
+
+	return err
+}
+
+// Close flushes the buffer, stops background flushing and closes underlying file
+func (bf *BufferedFile) Close() error {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	if bf.stop != nil {
+		close(bf.stop)
+		bf.stop = nil
+	}
+	err := bf.flushAll()
+	if cErr := bf.f.Close(); err == nil {
+		err = cErr
+	}
+
+	return err
+}
+
+// wrapFile returns f buffered according to p. In case f is already buffered,
+// it's flushed and unwrapped first. Zero policy returns unbuffered file.
+func wrapFile(f IFile, p FlushPolicy) (IFile, error) {
+	if bf, ok := f.(*BufferedFile); ok {
+		bf.mu.Lock()
+		if bf.stop != nil {
+			close(bf.stop)
+			bf.stop = nil
+		}
+		err := bf.flushAll()
+		bf.mu.Unlock()
+		if err != nil {
+			return f, err
+		}
+		f = bf.f
+	}
+	if !p.buffered() {
+		return f, nil
+	}
+
+	return NewBufferedFile(f, p), nil
+}
+
+// fileWritten tells f that event of level l was written in case f is BufferedFile
+func fileWritten(f IFile, l Level) error {
+	if bf, ok := f.(*BufferedFile); ok {
+		return bf.Written(l)
+	}
+
+	return nil
+}
+
+// flushFile flushes f in case it's BufferedFile
+func flushFile(f IFile) error {
+	if bf, ok := f.(*BufferedFile); ok {
+		return bf.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Data should be written only after MaxBytes is reached
+func TestBufferedFileMaxBytes(t *testing.T) {
+	m := &MockFile{}
+	bf := NewBufferedFile(m, FlushPolicy{MaxBytes: 10})
+
+	_, err := bf.WriteString("12345")
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(m.Text))
+
+	_, err = bf.WriteString("67890")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1234567890"}, m.Text)
+
+	_, err = bf.WriteString("abc")
+	require.NoError(t, err)
+	require.NoError(t, bf.Close())
+	assert.Equal(t, []string{"1234567890", "abc"}, m.Text)
+}
+
+// Record should never be split between writes to the file
+func TestBufferedFileRecords(t *testing.T) {
+	m := &MockFile{}
+	bf := NewBufferedFile(m, FlushPolicy{MaxBytes: 10})
+
+	for _, rec := range []string{"1234", "5678", "abcd", "0123456789ab", "xy"} {
+		_, err := bf.WriteString(rec)
+		require.NoError(t, err)
+	}
+	require.NoError(t, bf.Flush())
+	assert.Equal(t, []string{"12345678", "abcd", "0123456789ab", "xy"}, m.Text)
+}
+
+// failingFile fails writes while down is true
+type failingFile struct {
+	MockFile
+	down bool
+}
+
+var errDiskFull = errors.New("disk is full")
+
+func (f *failingFile) Write(b []byte) (int, error) {
+	if f.down {
+		return 0, errDiskFull
+	}
+
+	return f.MockFile.Write(b)
+}
+
+// Records should stay in buffer while file can not be written, flush errors should be returned by Flush
+func TestBufferedFileFailure(t *testing.T) {
+	f := &failingFile{down: true}
+	bf := NewBufferedFile(f, FlushPolicy{MaxBytes: 10})
+
+	var written []string
+	for _, rec := range []string{"12345", "67890", "abcdefghij", "klmnopqrst", "uvwxyz0123"} {
+		_, err := bf.WriteString(rec)
+		require.NoError(t, err)
+		written = append(written, rec)
+	}
+	_, err := bf.WriteString("456789abcd")
+	assert.ErrorIs(t, err, ErrFileBufferFull)
+	assert.ErrorIs(t, err, errDiskFull)
+	assert.ErrorIs(t, bf.Flush(), errDiskFull)
+
+	f.down = false
+	_, err = bf.WriteString("last")
+	require.NoError(t, err)
+	written = append(written, "last")
+	require.NoError(t, bf.Flush())
+	assert.Equal(t, strings.Join(written, ""), strings.Join(f.Text, ""))
+}
+
+// Data should be written right after event of high enough level
+func TestBufferedFileLevel(t *testing.T) {
+	m := &MockFile{}
+	bf := NewBufferedFile(m, FlushPolicy{Level: ERR})
+
+	_, err := bf.WriteString("info")
+	require.NoError(t, err)
+	require.NoError(t, bf.Written(INFO))
+	assert.Equal(t, 0, len(m.Text))
+
+	_, err = bf.WriteString("error")
+	require.NoError(t, err)
+	require.NoError(t, bf.Written(ERR))
+	assert.Equal(t, []string{"infoerror"}, m.Text)
+}
+
+// Data should be written to disk by interval even without new writes
+func TestBufferedFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "some.log")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	bf := NewBufferedFile(f, FlushPolicy{Interval: 20 * time.Millisecond, Sync: true})
+	defer bf.Close()
+
+	_, err = bf.WriteString("event1\n")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		b, err := os.ReadFile(path)
+		return err == nil && string(b) == "event1\n"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// File logger should keep records in buffer until flushed
+func TestLoggerFileTextFlushPolicy(t *testing.T) {
+	m := &MockFile{}
+	lg1, err := NewPlaintext("some", true, false, 0, m, Any)
+	require.NoError(t, err)
+	require.NoError(t, lg1.SetFlushPolicy(FlushPolicy{Level: CRIT}))
+
+	p := New(false, "", make(chan error), false, lg1)
+	p.Log(Info("event1"))
+	assert.Equal(t, 0, len(m.Text))
+
+	p.Log(Critical("event2"))
+	assert.Equal(t, []string{"event1\nevent2\n"}, m.Text)
+
+	p.Log(Info("event3"))
+	require.NoError(t, p.Close())
+	assert.Equal(t, "event3\n", m.Text[1])
+
+	//Zero policy should turn buffering off
+	require.NoError(t, lg1.SetFlushPolicy(FlushPolicy{}))
+	p.Log(Info("event4"))
+	assert.Equal(t, "event4\n", m.Text[2])
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"time"
 )
 
@@ -22,6 +23,10 @@ type Event struct {
 	//TimeFixed should be set to true if the app must log same event instance without updating
 	//event's Time value. E.g. for making several records with different text, but for same time.
 	TimeFixed bool
+
+	//Context is the context event happened in (e.g. with trace span), loggers may take data from it.
+	//It is not serialized
+	Context context.Context `json:"-"`
 }
 
 func (e Event) SetText(t string) Event {
@@ -53,6 +58,13 @@ func (e Event) Src(s Source) Event {
 	return e
 }
 
+// WithContext returns event with Context = ctx
+func (e Event) WithContext(ctx context.Context) Event {
+	e.Context = ctx
+
+	return e
+}
+
 // FlushID cleans event ID
 func (e Event) FlushID() Event {
 	e.ID = ""
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLockUnsupported is returned in case file can not be locked on current platform or it's not an OS file
+var ErrLockUnsupported = errors.New("file locking is not supported")
+
+// lockedFile is an IFile that takes exclusive advisory lock for every write, so records
+// of several processes writing into the same file are not interleaved
+type lockedFile struct {
+	f  IFile
+	fd uintptr
+}
+
+// newLockedFile returns f that is locked on every write. f should be an OS file (have Fd method)
+func newLockedFile(f IFile) (*lockedFile, error) {
+	fd, ok := f.(interface{ Fd() uintptr })
+	if !ok {
+		return nil, ErrLockUnsupported
+	}
+
+	return &lockedFile{f: f, fd: fd.Fd()}, nil
+}
+
+// Write writes b with a single call while file is locked
+func (lf *lockedFile) Write(b []byte) (int, error) {
+	if err := lockFile(lf.fd); err != nil {
+		return 0, fmt.Errorf("[lockedFile][Write] error locking file: %w", err)
+	}
+	defer unlockFile(lf.fd)
+
+	return lf.f.Write(b)
+}
+
+// WriteString writes s with a single call while file is locked
+func (lf *lockedFile) WriteString(s string) (int, error) {
+	return lf.Write([]byte(s))
+}
+
+// writeIfEmpty writes b only in case file is empty. Check & write are made under the same lock,
+// so only one of processes writes the header into new shared file
+func (lf *lockedFile) writeIfEmpty(b []byte) error {
+	if err := lockFile(lf.fd); err != nil {
+		return fmt.Errorf("[lockedFile][writeIfEmpty] error locking file: %w", err)
+	}
+	defer unlockFile(lf.fd)
+
+	if !isEmptyFile(lf.f) {
+		return nil
+	}
+	_, err := lf.f.Write(b)
+
+	return err
+}
+
+// Stat returns info of underlying file
+func (lf *lockedFile) Stat() (os.FileInfo, error) {
+	st, ok := lf.f.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return nil, ErrLockUnsupported
+	}
+
+	return st.Stat()
+}
+
+// Sync commits underlying file to disk
+func (lf *lockedFile) Sync() error {
+	if s, ok := lf.f.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// Close closes underlying file
+func (lf *lockedFile) Close() error {
+	return lf.f.Close()
+}
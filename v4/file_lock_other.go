@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package logger
+
+func lockFile(fd uintptr) error { return ErrLockUnsupported }
+
+func unlockFile(fd uintptr) error { return nil }
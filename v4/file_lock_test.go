@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Only OS files can be locked
+func TestLockedFileUnsupported(t *testing.T) {
+	_, err := NewRotatingFile("some", "log", true, &MockFile{}, RotationOptions{Lock: true})
+	assert.ErrorIs(t, err, ErrLockUnsupported)
+}
+
+// Several writers of one file should not interleave records and header should be written once
+func TestLockedFileSharedWriters(t *testing.T) {
+	dir := t.TempDir()
+	opts := RotationOptions{Lock: true, NameTemplate: "{path}.{ext}"}
+
+	const writers, records = 4, 200
+	var loggers []*CSVFileLogger
+	for i := 0; i < writers; i++ {
+		l, err := NewCSVRotating(filepath.Join(dir, "app"), false, opts, Any)
+		if errors.Is(err, ErrLockUnsupported) {
+			t.Skip(err)
+		}
+		require.NoError(t, err)
+		loggers = append(loggers, l)
+	}
+
+	var wg sync.WaitGroup
+	for i, l := range loggers {
+		wg.Add(1)
+		go func(i int, l *CSVFileLogger) {
+			defer wg.Done()
+			for n := 0; n < records; n++ {
+				e := Info(fmt.Sprintf("writer %d record %d %s", i, n, strings.Repeat("x", 512)))
+				assert.NoError(t, l.Log(e, "15:04:05"))
+			}
+		}(i, l)
+	}
+	wg.Wait()
+	for _, l := range loggers {
+		require.NoError(t, l.Close())
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "app.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	require.Equal(t, writers*records+1, len(lines))
+	assert.Equal(t, strings.TrimSuffix(CSVHead, "\n"), lines[0])
+	for _, line := range lines[1:] {
+		assert.Contains(t, line, strings.Repeat("x", 512))
+	}
+}
+
+// Buffered writers of one file should not interleave records, started writer should not truncate the file
+func TestLockedFileBufferedWriters(t *testing.T) {
+	dir := t.TempDir()
+	opts := RotationOptions{Lock: true, NameTemplate: "{path}.{ext}"}
+	name := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(name, []byte("old record\n"), 0666))
+
+	const writers, records = 4, 200
+	var loggers []*PlaintextFileLogger
+	for i := 0; i < writers; i++ {
+		l, err := NewPlaintextRotating(filepath.Join(dir, "app"), true, true, opts, Any)
+		if errors.Is(err, ErrLockUnsupported) {
+			t.Skip(err)
+		}
+		require.NoError(t, err)
+		//Buffer is not a multiple of record size, so records do not fit it evenly
+		require.NoError(t, l.SetFlushPolicy(FlushPolicy{MaxBytes: 1000}))
+		loggers = append(loggers, l)
+	}
+
+	var wg sync.WaitGroup
+	for i, l := range loggers {
+		wg.Add(1)
+		go func(i int, l *PlaintextFileLogger) {
+			defer wg.Done()
+			for n := 0; n < records; n++ {
+				e := Info(fmt.Sprintf("writer %d record %d %s", i, n, strings.Repeat("x", 300)))
+				assert.NoError(t, l.Log(e, "15:04:05"))
+			}
+		}(i, l)
+	}
+	wg.Wait()
+	for _, l := range loggers {
+		require.NoError(t, l.Close())
+	}
+
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	require.Equal(t, writers*records+1, len(lines))
+	assert.Equal(t, "old record", lines[0])
+	for _, line := range lines[1:] {
+		assert.True(t, strings.HasSuffix(line, strings.Repeat("x", 300)), line)
+	}
+}
+
+func TestFileNameFromTemplateProcess(t *testing.T) {
+	host, err := os.Hostname()
+	require.NoError(t, err)
+
+	name := FileNameFromTemplate("{path}-{host}-{pid}.{ext}", "app", "log", time.Now())
+	assert.Equal(t, "app-"+host+"-"+strconv.Itoa(os.Getpid())+".log", name)
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// ErrNotWritable is returned by file loggers in case log directory does not allow creating files
+var ErrNotWritable = errors.New("log directory is not writable")
+
+// filePerms determines mode & ownership of log files and directories created for them
+type filePerms struct {
+	//file & dir are modes set regardless of umask. Zero means default mode limited by umask
+	file os.FileMode
+	dir  os.FileMode
+
+	//gid is the group of new files & dirs, -1 means group is not changed
+	gid int
+}
+
+// newFilePerms returns filePerms of opts. Group is looked up by name first, then by numeric ID
+func newFilePerms(opts RotationOptions) (filePerms, error) {
+	p := filePerms{file: opts.FileMode.Perm(), dir: opts.DirMode.Perm(), gid: -1}
+	if opts.Group == "" {
+		return p, nil
+	}
+
+	g, err := user.LookupGroup(opts.Group)
+	if err != nil {
+		g, err = user.LookupGroupId(opts.Group)
+		if err != nil {
+			return p, fmt.Errorf("unknown group %s: %w", opts.Group, err)
+		}
+	}
+	p.gid, err = strconv.Atoi(g.Gid)
+	if err != nil {
+		return p, fmt.Errorf("group %s has no numeric ID: %w", opts.Group, err)
+	}
+
+	return p, nil
+}
+
+// fileMode returns mode for os.OpenFile
+func (p filePerms) fileMode() os.FileMode {
+	if p.file == 0 {
+		return 0666
+	}
+
+	return p.file
+}
+
+// apply sets mode & group of newly created file or directory at name
+func (p filePerms) apply(name string, mode os.FileMode) error {
+	if mode != 0 {
+		if err := os.Chmod(name, mode); err != nil {
+			return fmt.Errorf("can not set mode of %s: %w", name, err)
+		}
+	}
+	if p.gid >= 0 {
+		if err := os.Chown(name, -1, p.gid); err != nil {
+			return fmt.Errorf("can not set group of %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// mkdirAll makes dir with all missing parents, applying perms to every directory it creates
+func (p filePerms) mkdirAll(dir string) error {
+	if dir == "" || dir == "." {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := p.mkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	mode := p.dir
+	if mode == 0 {
+		mode = os.ModePerm
+	}
+	if err := os.Mkdir(dir, mode); err != nil {
+		//Could be made by another process meanwhile
+		if errors.Is(err, os.ErrExist) {
+			return nil
+		}
+		return err
+	}
+
+	return p.apply(dir, p.dir)
+}
+
+// validateDir makes dir and checks that files can be created in it
+func (p filePerms) validateDir(dir string) error {
+	if err := p.mkdirAll(dir); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotWritable, err)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, ".lazyevent-*")
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotWritable, err)
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotWritable, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Construction should fail in case files can not be created in log directory
+func TestRotatingFileNotWritable(t *testing.T) {
+	dir := t.TempDir()
+	notDir := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notDir, nil, 0600))
+
+	_, err := NewPlaintextRotating(filepath.Join(notDir, "app"), false, false, RotationOptions{}, Any)
+	assert.ErrorIs(t, err, ErrNotWritable)
+
+	_, err = NewPlaintextRotating(filepath.Join(dir, "app"), false, false, RotationOptions{
+		NameTemplate: "{path}/{YYYY}/{MM}/{ext}",
+	}, Any)
+	assert.NoError(t, err)
+}
+
+func TestRotatingFileUnknownGroup(t *testing.T) {
+	_, err := NewJSONRotating(filepath.Join(t.TempDir(), "app"), false, RotationOptions{
+		Group: "no-such-group-for-logs",
+	}, Any)
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Modes should be set regardless of umask, existing files should be left as is
+func TestRotatingFileModes(t *testing.T) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	l, err := NewCSVRotating(filepath.Join(dir, "logs", "app"), false, RotationOptions{
+		NameTemplate: "{path}.{ext}",
+		FileMode:     0644,
+		DirMode:      0755,
+		Group:        strconv.Itoa(os.Getgid()),
+	}, Any)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	info, err := os.Stat(filepath.Join(dir, "logs"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	name := filepath.Join(dir, "logs", "app.csv")
+	info, err = os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	assert.Equal(t, uint32(os.Getgid()), info.Sys().(*syscall.Stat_t).Gid)
+
+	require.NoError(t, os.Chmod(name, 0600))
+	l, err = NewCSVRotating(filepath.Join(dir, "logs", "app"), false, RotationOptions{
+		NameTemplate: "{path}.{ext}",
+		FileMode:     0644,
+	}, Any)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	info, err = os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// Without modes set umask should be respected
+func TestRotatingFileDefaultModes(t *testing.T) {
+	old := syscall.Umask(0027)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	l, err := NewCSVRotating(filepath.Join(dir, "app"), false, RotationOptions{NameTemplate: "{path}.{ext}"}, Any)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	info, err := os.Stat(filepath.Join(dir, "app.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPOptions are common options of loggers that send events over HTTP
+type HTTPOptions struct {
+	//Headers are added to every request
+	Headers map[string]string
+
+	//BearerToken or Username & Password set Authorization header
+	BearerToken string
+	Username    string
+	Password    string
+
+	//Timeout limits every request. Default is 10 seconds
+	Timeout time.Duration
+
+	//MaxRetries is the number of retries after network errors, 5xx & 429 responses.
+	//Other responses are not retried
+	MaxRetries int
+
+	//RetryDelay is the delay before first retry (1 second by default), each next is twice as long,
+	//but not longer than MaxRetryDelay (30 seconds by default). Retry-After header of response is respected
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+
+	//TLSConfig is used for https connections
+	TLSConfig *tls.Config
+
+	//Client replaces default client, Timeout & TLSConfig are ignored then
+	Client *http.Client
+}
+
+// HTTPStatusError is returned in case server responded with unsuccessful status
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server responded with %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// retryable returns true in case request may succeed later
+func (e *HTTPStatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// ErrSenderClosed is returned by HTTP loggers after Close, including requests interrupted by it
+var ErrSenderClosed = errors.New("logger is closed")
+
+const (
+	//maxErrorBody limits size of response body kept in HTTPStatusError
+	maxErrorBody = 4 << 10
+
+	//maxResponseBody limits size of successful response body
+	maxResponseBody = 32 << 20
+)
+
+// httpSender makes requests with retries for HTTP loggers
+type httpSender struct {
+	opts   HTTPOptions
+	client *http.Client
+
+	//ctx is cancelled by close: requests & retry delays are interrupted
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	//sleep waits for d and returns false in case sender was closed meanwhile
+	sleep func(d time.Duration) bool
+}
+
+func newHTTPSender(opts HTTPOptions) *httpSender {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = time.Second
+	}
+	if opts.MaxRetryDelay <= 0 {
+		opts.MaxRetryDelay = 30 * time.Second
+	}
+	client := opts.Client
+	if client == nil {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = opts.TLSConfig
+		client = &http.Client{Timeout: opts.Timeout, Transport: tr}
+	}
+
+	s := &httpSender{opts: opts, client: client}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.sleep = s.wait
+
+	return s
+}
+
+// wait sleeps for d unless sender is closed. It returns false in case it was interrupted
+func (s *httpSender) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// close interrupts current requests & retry delays and makes next requests fail with ErrSenderClosed
+func (s *httpSender) close() { s.cancel() }
+
+// send makes request with body and returns body of successful response.
+// headers are added to the ones from HTTPOptions
+func (s *httpSender) send(method string, url string, headers map[string]string, body []byte) ([]byte, error) {
+	delay := s.opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if s.ctx.Err() != nil {
+			return nil, ErrSenderClosed
+		}
+		resp, wait, retry, err := s.do(method, url, headers, body)
+		if err == nil {
+			return resp, nil
+		}
+		if s.ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSenderClosed, err)
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			return nil, err
+		}
+
+		if wait <= 0 {
+			wait = delay
+			delay *= 2
+		}
+		if wait > s.opts.MaxRetryDelay {
+			wait = s.opts.MaxRetryDelay
+		}
+		if !s.sleep(wait) {
+			return nil, fmt.Errorf("%w: %v", ErrSenderClosed, err)
+		}
+	}
+}
+
+// do makes single request. In case of error it also returns the delay asked by Retry-After header
+// and whether request may succeed later: only network errors, 5xx & 429 responses are retried
+func (s *httpSender) do(method string, url string, headers map[string]string, body []byte) ([]byte, time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(s.ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, false, err
+	}
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if s.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.BearerToken)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, true, err
+	}
+	defer resp.Body.Close()
+
+	//Request is accepted: errors of reading the answer are not retried, so events are not sent twice
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody+1))
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("error reading response: %w", err)
+		}
+		if len(b) > maxResponseBody {
+			return nil, 0, false, fmt.Errorf("response is larger than %d bytes", maxResponseBody)
+		}
+		return b, 0, false, nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	se := &HTTPStatusError{StatusCode: resp.StatusCode, Body: string(b)}
+
+	return nil, retryAfter(resp.Header.Get("Retry-After")), se.retryable(), se
+}
+
+// retryAfter parses Retry-After header value: number of seconds or HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sendJournalMemfd writes msg into sealed memory file and passes its descriptor to journald.
+// It's the way of native protocol to send entries larger than datagram limit
+func sendJournalMemfd(conn *net.UnixConn, msg []byte) error {
+	fd, err := unix.MemfdCreate("journal-message", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("can not create memfd: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "journal-message")
+	defer f.Close()
+
+	if _, err := f.Write(msg); err != nil {
+		return fmt.Errorf("can not write memfd: %w", err)
+	}
+	//journald accepts only sealed memfds, so the data can't be changed after sending
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, seals); err != nil {
+		return fmt.Errorf("can not seal memfd: %w", err)
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("can not pass memfd: %w", err)
+	}
+	var sendErr error
+	err = raw.Write(func(s uintptr) bool {
+		sendErr = unix.Sendmsg(int(s), nil, unix.UnixRights(fd), nil, 0)
+		return sendErr != unix.EAGAIN
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		return fmt.Errorf("can not pass memfd: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+// sendJournalMemfd is not supported: journald exists only on Linux
+func sendJournalMemfd(conn *net.UnixConn, msg []byte) error {
+	return errors.New("journal entry is too large for a datagram")
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package logger
+
+// journalTooLarge is always false: platforms other than Unix have no journald, so datagram size
+// errors are returned as they are
+func journalTooLarge(err error) bool { return false }
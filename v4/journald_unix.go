@@ -0,0 +1,13 @@
+//go:build unix
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// journalTooLarge returns true in case err means entry does not fit into a datagram
+func journalTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
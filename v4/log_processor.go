@@ -1,8 +1,6 @@
 package logger
 
 import (
-	"fmt"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,14 +9,16 @@ import (
 // LogProcessor manages all available loggers, processes log errors
 // and sends events to external routine if needed
 type LogProcessor struct {
-	useID        bool
-	timeFormat   string
-	loggers      []ILogger
-	useChan      bool
-	evChan       chan (Event)
-	reportErrors bool
-	errChan      chan (error)
-	force        Force
+	useID      bool
+	timeFormat string
+	loggers    []*loggerEntry
+	useChan    bool
+	evChan     chan (Event)
+	errs       *errReporter
+	bus        *eventBus
+	term       *terminator
+	iso        *isolation
+	force      Force
 }
 
 type Force struct {
@@ -30,30 +30,32 @@ type Force struct {
 
 // New creates new LogProcessor with selected parameters.
 //
-// If timeFormat is an empty string, time.UnixDate will be used. If useID is false, events will have e,pty ID
+// If timeFormat is an empty string, time.UnixDate will be used. If useID is false, events will have e,pty ID.
+//
+// Logger errors are sent into errChan (as LogError) only if reportErrors is true. Sending never blocks
+// the log path, so errChan should be buffered, otherwise errors will be dropped while nobody reads the channel.
 func New(useID bool, timeFormat string, errChan chan (error), reportErrors bool, la ...ILogger) *LogProcessor {
 	evChan := make(chan (Event))
 	if timeFormat == "" {
 		timeFormat = time.UnixDate
 	}
-	p := &LogProcessor{useID: useID, timeFormat: timeFormat, evChan: evChan, errChan: errChan, reportErrors: reportErrors, loggers: la}
+	p := &LogProcessor{useID: useID, timeFormat: timeFormat, evChan: evChan, errs: newErrReporter(errChan, reportErrors), bus: newEventBus(), iso: &isolation{}, loggers: newLoggerEntries(la...)}
+	p.term = newTerminator(p.Flush)
 
 	return p
 }
 
-// SetErrChan sets new error channel to send log process errors.
-func (ep *LogProcessor) SetErrChan(evChan chan (Event)) {
+// SetEventChan sets channel to send every logged event into.
+//
+// Note: PANIC and FATAL events do not make app panic or exit while event channel is set,
+// it becomes a job of external routine.
+//
+// Deprecated: use Subscribe, it does not block and does not affect PANIC & FATAL behavior.
+func (ep *LogProcessor) SetEventChan(evChan chan (Event)) {
 	ep.useChan = true
 	ep.evChan = evChan
 }
 
-// UnSetErrChan prevents EP from sending log errors to outer routine and
-// closes the error channel
-func (ep *LogProcessor) UnSetErrChan() {
-	ep.useChan = false
-	close(ep.errChan)
-}
-
 // ForceSource makes EP forsibly change source of every event to s
 func (ep *LogProcessor) ForceSource(s Source) {
 	ep.force.forceSource = true
@@ -68,14 +70,28 @@ func (ep *LogProcessor) ForceLevel(l Level) {
 
 // AddLoggers adds list of loggers to EP's pool
 func (lp *LogProcessor) AddLoggers(la ...ILogger) {
-	lp.loggers = append(lp.loggers, la...)
+	lp.loggers = append(lp.loggers, newLoggerEntries(la...)...)
 }
 
 // Log logs event according to it's type and level.
 //
-// It panics after logging PANIC-level and calls to
-// exit(2) after logging FATAL event.
+// By default it panics after logging PANIC-level and calls to
+// exit(2) after logging FATAL event. Use SetTerminator to change this behavior.
 func (lp *LogProcessor) Log(e Event) {
+	e = lp.log(e)
+
+	//In case we use chan, we do not panic or exit - it will be job of
+	//external routine
+	if lp.useChan {
+		go lp.SendEventToChan(e)
+	} else {
+		lp.term.terminate(e)
+	}
+}
+
+// log prepares event, passes it to loggers & subscribers and returns
+// the event in the form it was logged. It never panics or exits.
+func (lp *LogProcessor) log(e Event) Event {
 	if lp.useID {
 		//Set ID for event to avoid ambiguity in logs.
 		//Skip in case it has custom ID
@@ -95,32 +111,15 @@ func (lp *LogProcessor) Log(e Event) {
 		e.Level = lp.force.level
 	}
 
-	var logTypes []LogType
-	for _, lg := range lp.loggers {
-		logTypes = lg.Type()
-		for _, lt := range logTypes {
-			if e.Type == lt || lt == Any || e.Type == Any {
-				if err := lg.Log(e, lp.timeFormat); err != nil && lp.reportErrors {
-					go func(err error) { lp.errChan <- fmt.Errorf("error making log record: %w", err) }(err)
-				}
-				break
-			}
+	for _, le := range lp.loggers {
+		if lp.accepts(le, e) {
+			lp.callLogger(le, e)
 		}
-
 	}
 
-	//In case we use chan, we do not panic or exit - it will be job of
-	//external routine
-	if lp.useChan {
-		go lp.SendEventToChan(e)
-	} else {
-		if e.Level == PANIC {
-			panic(e.Text)
-		}
-		if e.Level == FATAL {
-			os.Exit(2)
-		}
-	}
+	lp.bus.publish(e)
+
+	return e
 }
 
 func (lp *LogProcessor) SendEventToChan(e Event) {
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IBatchLogger is an optional interface for loggers that can process several events at once
+// more efficiently than one by one (SQL, HTTP, Redis, etc.).
+//
+// LogProcessor uses LogBatch for loggers with batching on (see SetBatching). Loggers that do not
+// implement IBatchLogger still can be batched: their Log method is called for every event of a batch.
+type IBatchLogger interface {
+	ILogger
+
+	//LogBatch processes events in the order they were logged
+	LogBatch(events []Event, timeFormat string) error
+}
+
+// BatchPolicy determines when batched events are passed to logger.
+// Batch is flushed as soon as any of limits is reached, zero limits are not checked.
+type BatchPolicy struct {
+	//MaxEvents is the number of events in a batch
+	MaxEvents int
+
+	//MaxBytes is the approximate size of events text in a batch
+	MaxBytes int
+
+	//MaxLatency is the time first event of a batch can wait before batch is flushed
+	MaxLatency time.Duration
+}
+
+// enabled returns true in case policy has at least one limit
+func (p BatchPolicy) enabled() bool {
+	return p.MaxEvents > 0 || p.MaxBytes > 0 || p.MaxLatency > 0
+}
+
+// logBatch passes events to lg.LogBatch or to lg.Log one by one in case lg is not IBatchLogger.
+// Panic on one event does not stop the rest of the batch, it's returned as LoggerPanic error.
+func logBatch(lg ILogger, events []Event, timeFormat string) error {
+	if bl, ok := lg.(IBatchLogger); ok {
+		return bl.LogBatch(events, timeFormat)
+	}
+
+	var errs []error
+	for _, e := range events {
+		e := e
+		if err := safeCall(func() error { return lg.Log(e, timeFormat) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d events failed: %w", len(errs), len(events), CompositeError{Errors: errs})
+	}
+
+	return nil
+}
+
+// eventSize returns approximate size of event in logs
+func eventSize(e Event) int {
+	return len(e.ID) + len(e.Text) + len(e.Source.Text) + len(e.Source.Open) + len(e.Source.Close) + 48
+}
+
+// batcher accumulates events of one logger and passes them to LogProcessor.deliver
+type batcher struct {
+	policy BatchPolicy
+	flushF func(events []Event)
+
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	events  []Event
+	bytes   int
+	timer   *time.Timer
+	closed  bool
+}
+
+func newBatcher(p BatchPolicy, flushF func(events []Event)) *batcher {
+	return &batcher{policy: p, flushF: flushF}
+}
+
+// add puts e into batch and flushes it in case limits are reached.
+// It returns false in case batcher is closed and event should be logged directly.
+func (b *batcher) add(e Event) bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	b.events = append(b.events, e)
+	b.bytes += eventSize(e)
+
+	full := (b.policy.MaxEvents > 0 && len(b.events) >= b.policy.MaxEvents) ||
+		(b.policy.MaxBytes > 0 && b.bytes >= b.policy.MaxBytes)
+	if !full && b.timer == nil && b.policy.MaxLatency > 0 {
+		b.timer = time.AfterFunc(b.policy.MaxLatency, b.flush)
+	}
+	b.mu.Unlock()
+
+	if full {
+		b.flush()
+	}
+
+	return true
+}
+
+// flush passes accumulated events to flushF. Batches are flushed one at a time to keep the order.
+func (b *batcher) flush() {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	events := b.events
+	b.events = nil
+	b.bytes = 0
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+
+	if len(events) > 0 {
+		b.flushF(events)
+	}
+}
+
+// close flushes accumulated events and makes add return false
+func (b *batcher) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	b.flush()
+}
+
+func (le *loggerEntry) getBatcher() *batcher {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	return le.batch
+}
+
+// SetBatching turns batching on for loggers in la (or for all loggers in the pool in case la is empty).
+// Events are accumulated for each logger separately and passed to it when any of p limits is reached.
+// Loggers that implement IBatchLogger receive whole batch via LogBatch, others receive events one by one.
+//
+// Loggers in la are matched the same way as in SetIsolation.
+//
+// Policy without limits turns batching off. Accumulated events are flushed on Close, so it should be
+// called before app exits.
+func (lp *LogProcessor) SetBatching(p BatchPolicy, la ...ILogger) {
+	for _, le := range lp.loggers {
+		set := len(la) == 0
+		for _, lg := range la {
+			if sameLogger(le.lg, lg) {
+				set = true
+			}
+		}
+		if !set {
+			continue
+		}
+
+		var nb *batcher
+		if p.enabled() {
+			entry := le
+			nb = newBatcher(p, func(events []Event) { lp.deliver(entry, events, true) })
+		}
+
+		le.mu.Lock()
+		old := le.batch
+		le.batch = nb
+		le.mu.Unlock()
+
+		if old != nil {
+			old.close()
+		}
+	}
+}
+
+// Flush passes all accumulated batches to loggers and flushes loggers that implement IFlusher.
+// Errors are reported the same way as logging errors.
+func (lp *LogProcessor) Flush() {
+	for _, le := range lp.loggers {
+		if b := le.getBatcher(); b != nil {
+			b.flush()
+		}
+	}
+	lp.flushLoggers()
+}
+
+// flushLoggers calls Flush of every logger that implements IFlusher
+func (lp *LogProcessor) flushLoggers() {
+	for _, le := range lp.loggers {
+		fl, ok := le.lg.(IFlusher)
+		if !ok {
+			continue
+		}
+		if err := safeCall(fl.Flush); err != nil {
+			lp.errs.report(LogError{Logger: le.lg, Err: err})
+		}
+	}
+}
+
+// Close flushes accumulated batches, turns batching off and flushes loggers that implement IFlusher.
+// Events logged after Close are passed to loggers directly.
+func (lp *LogProcessor) Close() error {
+	for _, le := range lp.loggers {
+		le.mu.Lock()
+		b := le.batch
+		le.batch = nil
+		le.mu.Unlock()
+
+		if b != nil {
+			b.close()
+		}
+	}
+	lp.flushLoggers()
+
+	return nil
+}
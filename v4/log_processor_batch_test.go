@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchLogger records batches it has received
+type batchLogger struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (l *batchLogger) Log(e Event, timeFormat string) error {
+	return l.LogBatch([]Event{e}, timeFormat)
+}
+
+func (l *batchLogger) LogBatch(events []Event, timeFormat string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var texts []string
+	for _, e := range events {
+		texts = append(texts, e.Text)
+	}
+	l.batches = append(l.batches, texts)
+
+	return l.err
+}
+
+func (l *batchLogger) Type() []LogType { return []LogType{Any} }
+
+func (l *batchLogger) getBatches() [][]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.batches
+}
+
+// Batch should be flushed after MaxEvents and rest on Close
+func TestLogProcessorBatchMaxEvents(t *testing.T) {
+	lg1 := &batchLogger{}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetBatching(BatchPolicy{MaxEvents: 2})
+
+	p.Log(Info("event1"))
+	assert.Equal(t, 0, len(lg1.getBatches()))
+	p.Log(Info("event2"))
+	p.Log(Info("event3"))
+	assert.Equal(t, [][]string{{"event1", "event2"}}, lg1.getBatches())
+
+	require.NoError(t, p.Close())
+	assert.Equal(t, [][]string{{"event1", "event2"}, {"event3"}}, lg1.getBatches())
+
+	//After close events should go directly
+	p.Log(Info("event4"))
+	assert.Equal(t, []string{"event4"}, lg1.getBatches()[2])
+}
+
+// Batch should be flushed after MaxLatency even if nothing else is logged
+func TestLogProcessorBatchMaxLatency(t *testing.T) {
+	lg1 := &batchLogger{}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetBatching(BatchPolicy{MaxEvents: 100, MaxLatency: 50 * time.Millisecond}, lg1)
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	assert.Eventually(t, func() bool { return len(lg1.getBatches()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"event1", "event2"}, lg1.getBatches()[0])
+}
+
+// Loggers without LogBatch should receive events one by one, errors should be reported with batch
+func TestLogProcessorBatchFallback(t *testing.T) {
+	lg1 := &MockLogger{LogType: []LogType{Any}, Err: fmt.Errorf("some logger error")}
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1)
+	p.SetBatching(BatchPolicy{MaxBytes: 1})
+
+	p.Log(Info("event1"))
+	assert.Equal(t, "event1", lg1.LoggedData.Text)
+
+	var le LogError
+	require.ErrorAs(t, <-errChan, &le)
+	assert.Equal(t, 1, len(le.Events))
+}
+
+// panicOnceLogger panics on event with text panic and records other events
+type panicOnceLogger struct {
+	texts []string
+}
+
+func (l *panicOnceLogger) Log(e Event, timeFormat string) error {
+	if e.Text == "panic" {
+		panic("logger is broken")
+	}
+	l.texts = append(l.texts, e.Text)
+	return nil
+}
+func (l *panicOnceLogger) Type() []LogType { return []LogType{Any} }
+
+// Panic on one event should not stop the rest of batch of logger without LogBatch
+func TestLogProcessorBatchFallbackPanic(t *testing.T) {
+	lg1 := &panicOnceLogger{}
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1)
+	p.SetBatching(BatchPolicy{MaxEvents: 3})
+
+	p.Log(Info("event1"))
+	p.Log(Info("panic"))
+	p.Log(Info("event3"))
+	assert.Equal(t, []string{"event1", "event3"}, lg1.texts)
+
+	var lpanic LoggerPanic
+	require.ErrorAs(t, <-errChan, &lpanic)
+}
+
+// Batch dropped because logger is unhealthy should be reported
+func TestLogProcessorBatchUnhealthy(t *testing.T) {
+	lg1 := &batchLogger{err: fmt.Errorf("some logger error")}
+	errChan := make(chan error, 2)
+	p := New(false, "", errChan, true, lg1)
+	p.SetIsolation(IsolationPolicy{MaxFailures: 1})
+	p.SetBatching(BatchPolicy{MaxEvents: 2})
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	p.Log(Info("event3"))
+	p.Log(Info("event4"))
+	assert.Equal(t, 1, len(lg1.getBatches()))
+
+	assert.Error(t, <-errChan)
+	var le LogError
+	require.ErrorAs(t, <-errChan, &le)
+	assert.ErrorIs(t, le, ErrLoggerUnhealthy)
+	assert.Equal(t, 2, len(le.Events))
+	assert.Equal(t, "event4", le.Event.Text)
+}
+
+// Batches should be flushed before termination
+func TestLogProcessorBatchTerminate(t *testing.T) {
+	lg1 := &batchLogger{}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetTestMode(true)
+	p.SetBatching(BatchPolicy{MaxEvents: 100})
+
+	p.Log(Info("event1"))
+	p.Log(Fatal("event2"))
+	assert.Equal(t, [][]string{{"event1", "event2"}}, lg1.getBatches())
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// LogError is an error that occurred while one of loggers was processing an event.
+// It is sent into error channel and passed to error handler of LogProcessor.
+//
+// Use errors.As to get LogError from error received via channel.
+type LogError struct {
+	//Logger is the logger that returned an error
+	Logger ILogger
+	//Event is the event logger failed to process
+	Event Event
+	//Err is the original error returned by the logger
+	Err error
+	//Events is the whole batch logger failed to process in case batching is on.
+	//Event is the last event of the batch then
+	Events []Event
+}
+
+// Error returns text representation of LogError with logger type and event ID (if any)
+func (le LogError) Error() string {
+	if le.Event.ID != "" {
+		return fmt.Sprintf("error making log record with %T for event %s: %v", le.Logger, le.Event.ID, le.Err)
+	}
+
+	return fmt.Sprintf("error making log record with %T: %v", le.Logger, le.Err)
+}
+
+// Unwrap returns the original error returned by the logger
+func (le LogError) Unwrap() error { return le.Err }
+
+// errReporter delivers log errors to external routine without blocking the log path.
+//
+// Errors are sent into channel with non-blocking send, so the channel capacity
+// is the limit of errors waiting to be read. Errors that did not fit are dropped
+// and counted.
+type errReporter struct {
+	mu      sync.RWMutex
+	enabled bool
+	ch      chan (error)
+	handler func(LogError)
+	dropped atomic.Uint64
+}
+
+func newErrReporter(errChan chan (error), enabled bool) *errReporter {
+	return &errReporter{ch: errChan, enabled: enabled}
+}
+
+// report passes le to error handler and sends it into error channel.
+// It never blocks unless error handler does.
+//
+// Handler is called without holding the lock, so it may change error settings itself.
+// Channel send stays under the lock: UnSetErrChan must not close the channel in the middle of it.
+func (r *errReporter) report(le LogError) {
+	r.mu.RLock()
+	handler := r.handler
+	r.mu.RUnlock()
+
+	if handler != nil {
+		handler(le)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.enabled || r.ch == nil {
+		return
+	}
+
+	select {
+	case r.ch <- le:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// SetErrChan sets new error channel to send log process errors and turns error reporting on.
+//
+// Errors are sent without blocking: in case channel is full (or unbuffered and nobody reads it at the moment)
+// error will be dropped and counted in DroppedErrors(). So buffered channel is the best option here.
+//
+// Previous channel is not closed, it's a job of the routine that has created it.
+func (lp *LogProcessor) SetErrChan(errChan chan (error)) {
+	lp.errs.mu.Lock()
+	defer lp.errs.mu.Unlock()
+
+	lp.errs.enabled = true
+	lp.errs.ch = errChan
+}
+
+// UnSetErrChan prevents LP from sending log errors to outer routine and
+// closes the error channel.
+//
+// It is safe to call UnSetErrChan while events are being logged: no more errors
+// will be sent into channel after it returns. Calling it twice does nothing.
+func (lp *LogProcessor) UnSetErrChan() {
+	lp.errs.mu.Lock()
+	defer lp.errs.mu.Unlock()
+
+	lp.errs.enabled = false
+	if lp.errs.ch != nil {
+		close(lp.errs.ch)
+		lp.errs.ch = nil
+	}
+}
+
+// SetErrHandler sets function that will be called each time any logger returns an error.
+// Handler is called synchronously from Log(), so it should not take long or call Log() itself.
+// It may change error settings (SetErrHandler, SetErrChan, UnSetErrChan) though.
+//
+// Handler is called regardless of error channel settings. Pass nil to remove handler.
+func (lp *LogProcessor) SetErrHandler(h func(LogError)) {
+	lp.errs.mu.Lock()
+	defer lp.errs.mu.Unlock()
+
+	lp.errs.handler = h
+}
+
+// DroppedErrors returns number of errors that were not sent into error channel
+// because it was full
+func (lp *LogProcessor) DroppedErrors() uint64 { return lp.errs.dropped.Load() }
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Errors should reach channel as LogError with logger & event inside
+func TestLogProcessorErrChan(t *testing.T) {
+	errLog := fmt.Errorf("some logger error")
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     errLog,
+	}
+
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1)
+
+	e := Info("event1")
+	p.Log(e)
+
+	err := <-errChan
+	var le LogError
+	require.True(t, errors.As(err, &le))
+	assert.Equal(t, lg1, le.Logger)
+	assert.Equal(t, e.Text, le.Event.Text)
+	assert.ErrorIs(t, err, errLog)
+	assert.Equal(t, uint64(0), p.DroppedErrors())
+}
+
+// Full channel should not block Log, errors should be counted instead
+func TestLogProcessorErrChanDropped(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1)
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	p.Log(Info("event3"))
+
+	assert.Equal(t, 1, len(errChan))
+	assert.Equal(t, uint64(2), p.DroppedErrors())
+}
+
+// Errors should not be sent if reporting is off, but handler should work anyway
+func TestLogProcessorErrHandler(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, false, lg1)
+
+	var handled []LogError
+	p.SetErrHandler(func(le LogError) { handled = append(handled, le) })
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+
+	assert.Equal(t, 0, len(errChan))
+	assert.Equal(t, 2, len(handled))
+	assert.Equal(t, "event2", handled[1].Event.Text)
+}
+
+// UnSetErrChan should close channel once and stop sending errors
+func TestLogProcessorUnSetErrChan(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+
+	errChan := make(chan error, 10)
+	p := New(false, "", errChan, true, lg1)
+
+	p.Log(Info("event1"))
+	p.UnSetErrChan()
+	p.UnSetErrChan()
+	p.Log(Info("event2"))
+
+	var received int
+	for range errChan {
+		received++
+	}
+	assert.Equal(t, 1, received)
+	assert.Equal(t, uint64(0), p.DroppedErrors())
+
+	errChan2 := make(chan error, 10)
+	p.SetErrChan(errChan2)
+	p.Log(Info("event3"))
+	assert.Equal(t, 1, len(errChan2))
+}
+
+// Handler should be able to change error settings without deadlock
+func TestLogProcessorErrHandlerReentrant(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+
+	errChan := make(chan error, 10)
+	p := New(false, "", errChan, true, lg1)
+
+	var handled int
+	p.SetErrHandler(func(le LogError) {
+		handled++
+		p.UnSetErrChan()
+		p.SetErrHandler(nil)
+	})
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+
+	assert.Equal(t, 1, handled)
+	_, open := <-errChan
+	assert.False(t, open)
+}
@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	//ErrLoggerTimeout is reported in case logger did not process an event in IsolationPolicy.Timeout
+	ErrLoggerTimeout = errors.New("logger timed out")
+
+	//ErrLoggerBusy is reported in case logger is still processing an event that has timed out before
+	ErrLoggerBusy = errors.New("logger is still processing previous event")
+
+	//ErrLoggerUnhealthy is reported in case batch is dropped because logger has become unhealthy
+	//while events were accumulated
+	ErrLoggerUnhealthy = errors.New("logger is unhealthy, batch was dropped")
+)
+
+// LoggerPanic is reported in case logger panicked while processing an event
+type LoggerPanic struct {
+	Value any
+	Stack []byte
+}
+
+func (lp LoggerPanic) Error() string { return fmt.Sprintf("logger panicked: %v", lp.Value) }
+
+// IsolationPolicy determines how LogProcessor treats loggers that fail or hang.
+// Zero policy means no timeout and logger is never marked as unhealthy.
+//
+// Panics in loggers are recovered and reported as errors regardless of the policy.
+type IsolationPolicy struct {
+	//Timeout limits time logger can take to process an event. In case it's exceeded, LogProcessor
+	//reports ErrLoggerTimeout and moves on, while logger keeps working in separate goroutine.
+	//Until that goroutine is done, logger receives no new events (ErrLoggerBusy is reported instead).
+	Timeout time.Duration
+
+	//MaxFailures is the number of failures in a row that makes logger unhealthy.
+	//Unhealthy loggers do not receive events.
+	MaxFailures int
+
+	//Cooldown is the time unhealthy logger is skipped. After that logger receives next event
+	//and becomes healthy in case of success. Zero means logger is skipped until ResetLoggerHealth is called.
+	Cooldown time.Duration
+}
+
+// LoggerHealth represents current state of logger in LogProcessor's pool
+type LoggerHealth struct {
+	Logger         ILogger
+	Healthy        bool
+	Failures       int
+	LastError      error
+	UnhealthySince time.Time
+}
+
+// loggerEntry holds logger and its health state
+type loggerEntry struct {
+	lg     ILogger
+	policy *IsolationPolicy
+	batch  *batcher
+
+	mu             sync.Mutex
+	stuck          bool
+	failures       int
+	lastErr        error
+	unhealthySince time.Time
+}
+
+func newLoggerEntries(la ...ILogger) []*loggerEntry {
+	entries := make([]*loggerEntry, 0, len(la))
+	for _, lg := range la {
+		entries = append(entries, &loggerEntry{lg: lg})
+	}
+
+	return entries
+}
+
+// isolation holds default policy for loggers that have no specific one
+type isolation struct {
+	mu  sync.RWMutex
+	def IsolationPolicy
+}
+
+func (lp *LogProcessor) policyFor(le *loggerEntry) IsolationPolicy {
+	lp.iso.mu.RLock()
+	defer lp.iso.mu.RUnlock()
+
+	if le.policy != nil {
+		return *le.policy
+	}
+
+	return lp.iso.def
+}
+
+// accepts returns true in case logger accepts type of e. Panic in logger's Type is reported
+// the same way as panic in Log and the event is not passed to the logger.
+func (lp *LogProcessor) accepts(le *loggerEntry, e Event) (ok bool) {
+	err := safeCall(func() error {
+		ok = TypeMatches(e.Type, le.lg.Type())
+		return nil
+	})
+	if err != nil {
+		lp.errs.report(LogError{Logger: le.lg, Event: e, Err: err})
+		return false
+	}
+
+	return ok
+}
+
+// sameLogger returns true in case a & b are the same logger. Loggers of types that can not be
+// compared with == (e.g. non-pointer structs holding slices) never match, so pointers should be used
+// to set policies for specific loggers.
+func sameLogger(a, b ILogger) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta != nil && !ta.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// callLogger passes e to logger (or to its batcher in case batching is on).
+func (lp *LogProcessor) callLogger(le *loggerEntry, e Event) {
+	if b := le.getBatcher(); b != nil && b.add(e) {
+		return
+	}
+
+	lp.deliver(le, []Event{e}, false)
+}
+
+// deliver passes events to logger, recovers its panic, watches for timeout and updates logger health.
+// Batched events are passed via LogBatch in case logger supports it.
+// All errors are reported via LogProcessor error reporting.
+func (lp *LogProcessor) deliver(le *loggerEntry, events []Event, batched bool) {
+	pol := lp.policyFor(le)
+
+	le.mu.Lock()
+	if !le.unhealthySince.IsZero() {
+		if pol.Cooldown <= 0 || time.Since(le.unhealthySince) < pol.Cooldown {
+			le.mu.Unlock()
+			//Single events are skipped silently, but batch has been accepted before logger became unhealthy
+			if batched {
+				lp.errs.report(LogError{Logger: le.lg, Event: events[len(events)-1], Err: ErrLoggerUnhealthy, Events: events})
+			}
+			return
+		}
+		//Cooldown has passed: let the logger try once more. Failures are not reset,
+		//so another failure will make it unhealthy again
+		le.unhealthySince = time.Time{}
+	}
+	stuck := le.stuck
+	le.mu.Unlock()
+
+	call := func() error { return le.lg.Log(events[0], lp.timeFormat) }
+	if batched {
+		call = func() error { return logBatch(le.lg, events, lp.timeFormat) }
+	}
+
+	var err error
+	if stuck {
+		err = ErrLoggerBusy
+	} else if pol.Timeout > 0 {
+		err = callWithTimeout(le, call, pol.Timeout)
+	} else {
+		err = safeCall(call)
+	}
+
+	le.mu.Lock()
+	if err == nil {
+		le.failures = 0
+		le.lastErr = nil
+	} else {
+		le.failures++
+		le.lastErr = err
+		if pol.MaxFailures > 0 && le.failures >= pol.MaxFailures && le.unhealthySince.IsZero() {
+			le.unhealthySince = time.Now()
+		}
+	}
+	le.mu.Unlock()
+
+	if err != nil {
+		lerr := LogError{Logger: le.lg, Event: events[len(events)-1], Err: err}
+		if batched {
+			lerr.Events = events
+		}
+		lp.errs.report(lerr)
+	}
+}
+
+func callWithTimeout(le *loggerEntry, call func() error, timeout time.Duration) error {
+	done := false
+	res := make(chan error, 1)
+	go func() {
+		err := safeCall(call)
+		le.mu.Lock()
+		done = true
+		le.stuck = false
+		le.mu.Unlock()
+		res <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-res:
+		return err
+	case <-timer.C:
+		le.mu.Lock()
+		if !done {
+			le.stuck = true
+		}
+		le.mu.Unlock()
+
+		return ErrLoggerTimeout
+	}
+}
+
+// safeCall calls logger via call and turns its panic into LoggerPanic error
+func safeCall(call func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = LoggerPanic{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return call()
+}
+
+// SetIsolation sets policy for loggers in la. In case la is empty, p becomes the default policy
+// for all loggers that have no specific one (including loggers that will be added later).
+//
+// Loggers are compared with ==, so it's better to use pointers as loggers: non-pointer loggers
+// that can not be compared (structs holding slices or maps) are never matched.
+func (lp *LogProcessor) SetIsolation(p IsolationPolicy, la ...ILogger) {
+	lp.iso.mu.Lock()
+	defer lp.iso.mu.Unlock()
+
+	if len(la) == 0 {
+		lp.iso.def = p
+		return
+	}
+
+	for _, le := range lp.loggers {
+		for _, lg := range la {
+			if sameLogger(le.lg, lg) {
+				pol := p
+				le.policy = &pol
+			}
+		}
+	}
+}
+
+// LoggerHealth returns health state of every logger in the pool
+func (lp *LogProcessor) LoggerHealth() []LoggerHealth {
+	health := make([]LoggerHealth, 0, len(lp.loggers))
+	for _, le := range lp.loggers {
+		le.mu.Lock()
+		health = append(health, LoggerHealth{
+			Logger:         le.lg,
+			Healthy:        le.unhealthySince.IsZero(),
+			Failures:       le.failures,
+			LastError:      le.lastErr,
+			UnhealthySince: le.unhealthySince,
+		})
+		le.mu.Unlock()
+	}
+
+	return health
+}
+
+// ResetLoggerHealth marks loggers in la as healthy and resets their failure counters.
+// In case la is empty, all loggers are reset.
+func (lp *LogProcessor) ResetLoggerHealth(la ...ILogger) {
+	for _, le := range lp.loggers {
+		reset := len(la) == 0
+		for _, lg := range la {
+			if sameLogger(le.lg, lg) {
+				reset = true
+			}
+		}
+		if !reset {
+			continue
+		}
+
+		le.mu.Lock()
+		le.failures = 0
+		le.lastErr = nil
+		le.unhealthySince = time.Time{}
+		le.mu.Unlock()
+	}
+}
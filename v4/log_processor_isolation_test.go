@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panickingLogger struct{}
+
+func (l *panickingLogger) Log(e Event, timeFormat string) error { panic("logger is broken") }
+func (l *panickingLogger) Type() []LogType                      { return []LogType{Any} }
+
+type panickingTypeLogger struct{}
+
+func (l *panickingTypeLogger) Log(e Event, timeFormat string) error { return nil }
+func (l *panickingTypeLogger) Type() []LogType                      { panic("types are broken") }
+
+// sliceLogger is a non-pointer logger that can not be compared with ==
+type sliceLogger struct {
+	lTypes []LogType
+}
+
+func (l sliceLogger) Log(e Event, timeFormat string) error { return nil }
+func (l sliceLogger) Type() []LogType                      { return l.lTypes }
+
+type hangingLogger struct {
+	release chan struct{}
+}
+
+func (l *hangingLogger) Log(e Event, timeFormat string) error {
+	<-l.release
+	return nil
+}
+func (l *hangingLogger) Type() []LogType { return []LogType{Any} }
+
+// Panic in one logger should be reported and should not affect other loggers
+func TestLogProcessorLoggerPanic(t *testing.T) {
+	lg1 := &panickingLogger{}
+	lg2 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1, lg2)
+
+	assert.NotPanics(t, func() { p.Log(Info("event1")) })
+	assert.Equal(t, "event1", lg2.LoggedData.Text)
+
+	err := <-errChan
+	var lpanic LoggerPanic
+	require.True(t, errors.As(err, &lpanic))
+	assert.Equal(t, "logger is broken", lpanic.Value)
+}
+
+// Hanging logger should not block Log longer than timeout
+func TestLogProcessorLoggerTimeout(t *testing.T) {
+	lg1 := &hangingLogger{release: make(chan struct{})}
+	errChan := make(chan error, 2)
+	p := New(false, "", errChan, true, lg1)
+	p.SetIsolation(IsolationPolicy{Timeout: 50 * time.Millisecond}, lg1)
+
+	start := time.Now()
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	assert.Equal(t, true, time.Since(start) < time.Second)
+
+	assert.ErrorIs(t, <-errChan, ErrLoggerTimeout)
+	assert.ErrorIs(t, <-errChan, ErrLoggerBusy)
+
+	close(lg1.release)
+	assert.Eventually(t, func() bool {
+		p.Log(Info("event3"))
+		return p.LoggerHealth()[0].Failures == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Logger should become unhealthy after several failures and be skipped
+func TestLogProcessorLoggerUnhealthy(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+	lg2 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	p := New(false, "", make(chan error), false, lg1, lg2)
+	p.SetIsolation(IsolationPolicy{MaxFailures: 2})
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	p.Log(Info("event3"))
+
+	h := p.LoggerHealth()
+	assert.Equal(t, false, h[0].Healthy)
+	assert.Equal(t, 2, h[0].Failures)
+	assert.Equal(t, true, h[1].Healthy)
+	assert.Equal(t, "event2", lg1.LoggedData.Text)
+	assert.Equal(t, "event3", lg2.LoggedData.Text)
+
+	lg1.Err = nil
+	p.ResetLoggerHealth(lg1)
+	p.Log(Info("event4"))
+	assert.Equal(t, "event4", lg1.LoggedData.Text)
+	assert.Equal(t, true, p.LoggerHealth()[0].Healthy)
+}
+
+// Unhealthy logger should get another chance after cooldown
+func TestLogProcessorLoggerCooldown(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+		Err:     fmt.Errorf("some logger error"),
+	}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetIsolation(IsolationPolicy{MaxFailures: 1, Cooldown: 50 * time.Millisecond})
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+	assert.Equal(t, "event1", lg1.LoggedData.Text)
+
+	lg1.Err = nil
+	time.Sleep(100 * time.Millisecond)
+	p.Log(Info("event3"))
+	assert.Equal(t, "event3", lg1.LoggedData.Text)
+	assert.Equal(t, true, p.LoggerHealth()[0].Healthy)
+}
+
+// Panic in Type should be reported as logger panic
+func TestLogProcessorLoggerTypePanic(t *testing.T) {
+	lg1 := &panickingTypeLogger{}
+	lg2 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	errChan := make(chan error, 1)
+	p := New(false, "", errChan, true, lg1, lg2)
+
+	assert.NotPanics(t, func() { p.Log(Info("event1")) })
+	assert.Equal(t, "event1", lg2.LoggedData.Text)
+
+	var lpanic LoggerPanic
+	require.True(t, errors.As(<-errChan, &lpanic))
+	assert.Equal(t, "types are broken", lpanic.Value)
+}
+
+// Loggers that can not be compared should not make policy setters panic
+func TestLogProcessorUncomparableLogger(t *testing.T) {
+	lg1 := sliceLogger{lTypes: []LogType{Any}}
+	lg2 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	p := New(false, "", make(chan error), false, lg1, lg2)
+
+	assert.NotPanics(t, func() {
+		p.SetIsolation(IsolationPolicy{MaxFailures: 1}, lg1, lg2)
+		p.SetBatching(BatchPolicy{MaxEvents: 2}, lg1)
+		p.ResetLoggerHealth(lg1)
+	})
+	assert.Nil(t, p.loggers[0].policy)
+	assert.NotNil(t, p.loggers[1].policy)
+	assert.Nil(t, p.loggers[0].getBatcher())
+}
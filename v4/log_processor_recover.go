@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Recover logs panic (if any) as CRIT event with recovered value and stack trace
+// and lets the routine continue. It must be called with defer:
+//
+//	defer lp.Recover(src)
+//
+// Event goes to every logger as usual, but does not make app exit or panic
+// whatever Terminator is set.
+func (lp *LogProcessor) Recover(src Source) {
+	if r := recover(); r != nil {
+		lp.logRecovered(CRIT, r, src)
+	}
+}
+
+// RecoverAndPanic logs panic (if any) as PANIC event with recovered value and stack trace
+// and then panics again with the original value. It must be called with defer:
+//
+//	defer lp.RecoverAndPanic(src)
+//
+// It's useful when routine should still die, but leave a record in every logger before.
+// Batches and logger buffers are flushed before panic, same way as before PANIC termination.
+func (lp *LogProcessor) RecoverAndPanic(src Source) {
+	if r := recover(); r != nil {
+		lp.logRecovered(PANIC, r, src)
+		lp.Flush()
+		panic(r)
+	}
+}
+
+// Go runs f in new goroutine and logs panic in case f panics (see Recover).
+// src is used as event source if provided.
+func (lp *LogProcessor) Go(f func(), src ...Source) {
+	s := EvsEmpty
+	if len(src) > 0 {
+		s = src[0]
+	}
+
+	go func() {
+		defer lp.Recover(s)
+		f()
+	}()
+}
+
+// logRecovered logs recovered value r without calling Terminator
+func (lp *LogProcessor) logRecovered(l Level, r any, src Source) {
+	e := Empty()
+	e.Level = l
+	e.Source = src
+	e.Text = FormatRecovered(r, debug.Stack())
+
+	e = lp.log(e)
+	if lp.useChan {
+		go lp.SendEventToChan(e)
+	}
+}
+
+// FormatRecovered returns text of event that is logged after recovering from panic
+func FormatRecovered(r any, stack []byte) string {
+	return fmt.Sprintf("panic recovered: %v\n%s", r, stack)
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Recover should log CRIT event with panic value & stack trace and stop the panic
+func TestLogProcessorRecover(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	p := New(false, "", make(chan error), false, lg1)
+
+	assert.NotPanics(t, func() {
+		defer p.Recover(EvsMain)
+		panic("something bad")
+	})
+
+	assert.Equal(t, CRIT, lg1.LoggedData.Level)
+	assert.Equal(t, EvsMain, lg1.LoggedData.Source)
+	assert.Equal(t, true, strings.HasPrefix(lg1.LoggedData.Text, "panic recovered: something bad\n"))
+	assert.Equal(t, true, strings.Contains(lg1.LoggedData.Text, "TestLogProcessorRecover"))
+}
+
+// RecoverAndPanic should log PANIC event and panic again with original value
+func TestLogProcessorRecoverAndPanic(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetTestMode(true)
+
+	type customPanic struct{ code int }
+
+	assert.PanicsWithValue(t, customPanic{code: 5}, func() {
+		defer p.RecoverAndPanic(EvsMain)
+		panic(customPanic{code: 5})
+	})
+
+	assert.Equal(t, PANIC, lg1.LoggedData.Level)
+	assert.Equal(t, 0, len(p.Terminations()))
+}
+
+// Batched PANIC record should reach logger before panic goes on
+func TestLogProcessorRecoverAndPanicBatching(t *testing.T) {
+	lg1 := &batchLogger{}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetBatching(BatchPolicy{MaxEvents: 100})
+
+	p.Log(Info("event1"))
+	assert.Panics(t, func() {
+		defer p.RecoverAndPanic(EvsMain)
+		panic("something bad")
+	})
+
+	batches := lg1.getBatches()
+	require.Equal(t, 1, len(batches))
+	require.Equal(t, 2, len(batches[0]))
+	assert.Equal(t, "event1", batches[0][0])
+	assert.True(t, strings.HasPrefix(batches[0][1], "panic recovered: something bad\n"))
+}
+
+// Go should log panic of the routine
+func TestLogProcessorGo(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+	s := p.Subscribe(EventFilter{}, 1)
+
+	p.Go(func() { panic("routine failed") }, EvsDebug)
+
+	e := <-s.C()
+	require.Equal(t, CRIT, e.Level)
+	assert.Equal(t, EvsDebug, e.Source)
+	assert.Equal(t, true, strings.HasPrefix(e.Text, "panic recovered: routine failed\n"))
+}
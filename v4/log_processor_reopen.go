@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// IReopener is implemented by loggers that can close and reopen their files,
+// e.g. after the file was moved away by logrotate.
+type IReopener interface {
+	Reopen() error
+}
+
+// Reopen makes all loggers that implement IReopener reopen their files.
+// Errors are reported the same way as logging errors (see SetErrChan & SetErrHandler).
+func (lp *LogProcessor) Reopen() {
+	for _, le := range lp.loggers {
+		r, ok := le.lg.(IReopener)
+		if !ok {
+			continue
+		}
+		if err := safeCall(r.Reopen); err != nil {
+			lp.errs.report(LogError{Logger: le.lg, Err: err})
+		}
+	}
+}
+
+// ReopenOnSignal calls Reopen every time one of sig is received. In case sig is empty,
+// SIGHUP & SIGUSR1 are used (no signals on platforms other than Unix). Call returned func to stop listening.
+//
+// Configure logrotate with "create" or "copytruncate" mode and postrotate script sending the signal.
+func (lp *LogProcessor) ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = reopenSignals
+	}
+	if len(sig) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+				lp.Reopen()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// reopenSignals are default signals of ReopenOnSignal. Platforms other than Unix have no signals to reopen files
+var reopenSignals []os.Signal
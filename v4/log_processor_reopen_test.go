@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockReopener counts Reopen calls
+type mockReopener struct {
+	MockLogger
+	reopened atomic.Int32
+	err      error
+}
+
+func (m *mockReopener) Reopen() error {
+	m.reopened.Add(1)
+	return m.err
+}
+
+// Logger files should be recreated at the same path after they were moved away
+func TestLogProcessorReopen(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	l, err := NewPlaintextRotating(filepath.Join(dir, "app"), true, false, RotationOptions{NameTemplate: "{path}.{ext}"}, Any)
+	require.NoError(t, err)
+	p := New(false, "", make(chan error), false, l)
+
+	p.Log(Info("event1"))
+	require.NoError(t, os.Rename(name, name+".1"))
+	p.Log(Info("event2"))
+	p.Reopen()
+	p.Log(Info("event3"))
+	require.NoError(t, l.Close())
+
+	b, err := os.ReadFile(name + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "event1\nevent2\n", string(b))
+	b, err = os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "event3\n", string(b))
+}
+
+// Reopen errors should be reported and other loggers reopened anyway
+func TestLogProcessorReopenErrors(t *testing.T) {
+	failing := &mockReopener{err: errors.New("no way")}
+	ok := &mockReopener{}
+	p := New(false, "", make(chan error), false, failing, ok, &MockLogger{})
+
+	var reported []LogError
+	p.SetErrHandler(func(le LogError) { reported = append(reported, le) })
+	p.Reopen()
+
+	assert.Equal(t, int32(1), failing.reopened.Load())
+	assert.Equal(t, int32(1), ok.reopened.Load())
+	require.Equal(t, 1, len(reported))
+	assert.Equal(t, failing, reported[0].Logger)
+	assert.ErrorIs(t, reported[0], failing.err)
+}
+
+// Stable symlink should point to current file after every rotation
+func TestRotatingFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "app.log")
+	rf, err := NewRotatingFile(filepath.Join(dir, "app"), "log", true, nil, RotationOptions{
+		NameTemplate: "{path}-{ss}.{ext}",
+		Symlink:      link,
+	})
+	if err != nil && errors.Is(err, os.ErrPermission) {
+		t.Skip("symlinks are not permitted:", err)
+	}
+	require.NoError(t, err)
+	start := time.Date(2026, 10, 18, 12, 0, 1, 0, time.UTC)
+	now := start
+	rf.now = func() time.Time { return now }
+
+	require.NoError(t, rf.Write([]byte("event1\n"), INFO))
+	now = start.Add(time.Second)
+	require.NoError(t, rf.Rotate())
+	require.NoError(t, rf.Write([]byte("event2\n"), INFO))
+	require.NoError(t, rf.Close())
+
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "app-02.log", target)
+	b, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "event2\n", string(b))
+}
+
+// Symlink that can be the name of log file should be rejected, regular file should not be replaced
+func TestRotatingFileSymlinkCollision(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app")
+	for _, c := range []struct{ tmpl, link string }{
+		{"{path}.{ext}", path + ".log"},
+		{"{path}.{ext}", filepath.Join(dir, ".", "sub", "..", "app.log")},
+		{"{path}-{date}.{ext}", path + "-2026-10-19.log"},
+		{"{path}-{YYYY}{MM}.{ext}.tmp", path + "-202610.log"},
+		{"", path + "-2026_10_19_1_2_3.log"},
+	} {
+		_, err := NewRotatingFile(path, "log", false, nil, RotationOptions{NameTemplate: c.tmpl, Symlink: c.link})
+		assert.Error(t, err, c.link)
+	}
+
+	link := path + ".log"
+	require.NoError(t, os.WriteFile(link, []byte("old records\n"), 0666))
+	_, err := NewRotatingFile(path, "log", false, nil, RotationOptions{NameTemplate: "{path}-{date}.{ext}", Symlink: link})
+	assert.Error(t, err)
+	b, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "old records\n", string(b))
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenSignals are default signals of ReopenOnSignal
+var reopenSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1}
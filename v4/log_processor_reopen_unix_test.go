@@ -0,0 +1,22 @@
+//go:build unix
+
+package logger
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogProcessorReopenOnSignal(t *testing.T) {
+	r := &mockReopener{}
+	p := New(false, "", make(chan error), false, r)
+
+	stop := p.ReopenOnSignal(syscall.SIGUSR1)
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return r.reopened.Load() == 1 }, time.Second, 10*time.Millisecond)
+	stop()
+	stop()
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventFilter determines which events a subscriber will receive.
+// Empty list means any value of that property is ok.
+type EventFilter struct {
+	Levels  []Level
+	Types   []LogType
+	Sources []Source
+}
+
+// Match returns true in case e suits the filter.
+//
+// Types are matched the same way LogProcessor does with loggers: Any-typed event
+// matches any type and Any in the filter matches any event type.
+func (f EventFilter) Match(e Event) bool {
+	if len(f.Levels) > 0 {
+		found := false
+		for _, l := range f.Levels {
+			if e.Level == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Types) > 0 && !TypeMatches(e.Type, f.Types) {
+		return false
+	}
+
+	if len(f.Sources) > 0 {
+		found := false
+		for _, s := range f.Sources {
+			if e.Source == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Subscription is an external consumer of logged events. Events are received
+// via channel returned by C().
+type Subscription struct {
+	id      uint64
+	filter  EventFilter
+	ch      chan (Event)
+	dropped atomic.Uint64
+	bus     *eventBus
+}
+
+// C returns channel to read events from. Channel is closed after Unsubscribe() is called.
+func (s *Subscription) C() <-chan Event { return s.ch }
+
+// Dropped returns number of events that were not sent to subscriber because its channel was full
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Unsubscribe stops sending events to the subscriber and closes its channel.
+// It's safe to call Unsubscribe several times.
+func (s *Subscription) Unsubscribe() { s.bus.remove(s.id) }
+
+// eventBus sends logged events to all subscribers without blocking the log path
+type eventBus struct {
+	mu     sync.RWMutex
+	lastID uint64
+	subs   map[uint64]*Subscription
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[uint64]*Subscription)}
+}
+
+func (b *eventBus) add(f EventFilter, bufSize int) *Subscription {
+	if bufSize < 0 {
+		bufSize = 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	s := &Subscription{id: b.lastID, filter: f, ch: make(chan Event, bufSize), bus: b}
+	b.subs[s.id] = s
+
+	return s
+}
+
+func (b *eventBus) remove(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.subs[id]
+	if !ok {
+		return
+	}
+	delete(b.subs, id)
+	close(s.ch)
+}
+
+// publish sends e to every subscriber which filter matches the event.
+// Events that do not fit into subscriber channel are dropped.
+func (b *eventBus) publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe creates new subscription that will receive every logged event which suits f.
+// bufSize sets capacity of subscription channel.
+//
+// Events are sent after all loggers have processed them. LogProcessor never waits
+// for subscriber: in case its channel is full, event is dropped and counted in Subscription.Dropped().
+// Subscriptions do not change PANIC & FATAL behavior.
+func (lp *LogProcessor) Subscribe(f EventFilter, bufSize int) *Subscription {
+	return lp.bus.add(f, bufSize)
+}
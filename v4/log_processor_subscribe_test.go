@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilterMatch(t *testing.T) {
+	assert.Equal(t, true, EventFilter{}.Match(Info("event1")))
+
+	f := EventFilter{
+		Levels:  []Level{ERR, CRIT},
+		Types:   []LogType{ErrorFlow},
+		Sources: []Source{EvsMain},
+	}
+	assert.Equal(t, true, f.Match(Error("event1").Src(EvsMain)))
+	assert.Equal(t, false, f.Match(Info("event1").Src(EvsMain)))
+	assert.Equal(t, false, f.Match(Error("event1").Src(EvsDebug)))
+	assert.Equal(t, false, f.Match(Error("event1").Src(EvsMain).Debug()))
+
+	//Any-typed events should match any type, same as with loggers
+	assert.Equal(t, true, EventFilter{Types: []LogType{Debug}}.Match(Info("event1").Any()))
+	assert.Equal(t, true, EventFilter{Types: []LogType{Any}}.Match(Info("event1").Debug()))
+}
+
+// Every subscriber should receive only suitable events
+func TestLogProcessorSubscribe(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+
+	all := p.Subscribe(EventFilter{}, 10)
+	errs := p.Subscribe(EventFilter{Levels: []Level{ERR}}, 10)
+
+	p.Log(Info("event1"))
+	p.Log(Error("event2"))
+
+	assert.Equal(t, 2, len(all.C()))
+	assert.Equal(t, 1, len(errs.C()))
+	assert.Equal(t, "event2", (<-errs.C()).Text)
+}
+
+// Full subscriber should not block Log, events should be counted instead
+func TestLogProcessorSubscribeDropped(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+
+	s := p.Subscribe(EventFilter{}, 1)
+	unbuf := p.Subscribe(EventFilter{}, 0)
+
+	p.Log(Info("event1"))
+	p.Log(Info("event2"))
+
+	assert.Equal(t, "event1", (<-s.C()).Text)
+	assert.Equal(t, uint64(1), s.Dropped())
+	assert.Equal(t, uint64(2), unbuf.Dropped())
+}
+
+// Unsubscribe should close channel and stop sending events
+func TestLogProcessorUnsubscribe(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+
+	s := p.Subscribe(EventFilter{}, 10)
+	p.Log(Info("event1"))
+	s.Unsubscribe()
+	s.Unsubscribe()
+	p.Log(Info("event2"))
+
+	var received int
+	for range s.C() {
+		received++
+	}
+	assert.Equal(t, 1, received)
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Terminator determines how LogProcessor terminates the app after logging
+// PANIC or FATAL events.
+type Terminator struct {
+	//Exit is called to make app exit. Default is os.Exit
+	Exit func(code int)
+
+	//PanicValue returns value to panic with after PANIC event is logged.
+	//Default is event text, use EventPanicValue to panic with EventPanic
+	PanicValue func(e Event) any
+
+	//ExitCodes lists event levels that make app exit and exit code for each level.
+	//Default is FATAL -> 2. Levels listed here are checked before PANIC,
+	//so it's possible to make PANIC events exit instead of panicking.
+	ExitCodes map[Level]int
+
+	//HookTimeout limits time OnFatal hooks can take before exit. Default is 5 seconds
+	HookTimeout time.Duration
+}
+
+// DefaultTerminator returns Terminator that makes app panic with event text
+// after PANIC event and call os.Exit(2) after FATAL
+func DefaultTerminator() Terminator {
+	return Terminator{
+		Exit:        os.Exit,
+		PanicValue:  func(e Event) any { return e.Text },
+		ExitCodes:   map[Level]int{FATAL: 2},
+		HookTimeout: 5 * time.Second,
+	}
+}
+
+// withDefaults fills empty Terminator fields with default values
+func (t Terminator) withDefaults() Terminator {
+	d := DefaultTerminator()
+	if t.Exit == nil {
+		t.Exit = d.Exit
+	}
+	if t.PanicValue == nil {
+		t.PanicValue = d.PanicValue
+	}
+	if t.ExitCodes == nil {
+		t.ExitCodes = d.ExitCodes
+	}
+	if t.HookTimeout <= 0 {
+		t.HookTimeout = d.HookTimeout
+	}
+
+	return t
+}
+
+// EventPanic is a panic value that holds the event caused panic
+type EventPanic struct {
+	Event Event
+}
+
+func (ep EventPanic) Error() string {
+	return fmt.Sprintf("panic after logging event: %s", ep.Event.Text)
+}
+
+// EventPanicValue can be used as Terminator.PanicValue to panic with EventPanic instead of string
+func EventPanicValue(e Event) any { return EventPanic{Event: e} }
+
+// Termination is a record of app termination that was requested by LogProcessor in test mode
+type Termination struct {
+	//Event is the event that caused termination
+	Event Event
+	//Panic is true in case app should have panicked and false in case of exit
+	Panic bool
+	//PanicValue is the value app should have panicked with
+	PanicValue any
+	//ExitCode is the code app should have exited with
+	ExitCode int
+}
+
+// terminator makes app panic or exit after specific events are logged
+type terminator struct {
+	mu       sync.RWMutex
+	cfg      Terminator
+	hooks    []func(Event)
+	testMode bool
+	records  []Termination
+	//flush passes accumulated events to loggers before app terminates
+	flush func()
+}
+
+func newTerminator(flush func()) *terminator {
+	return &terminator{cfg: DefaultTerminator(), flush: flush}
+}
+
+// terminate does nothing in case e should not terminate the app. Otherwise it flushes batched events,
+// runs OnFatal hooks (for exit only) and exits or panics. In test mode termination is recorded instead.
+func (t *terminator) terminate(e Event) {
+	t.mu.RLock()
+	cfg := t.cfg
+	hooks := t.hooks
+	testMode := t.testMode
+	t.mu.RUnlock()
+
+	if code, ok := cfg.ExitCodes[e.Level]; ok {
+		t.flush()
+		runHooks(e, hooks, cfg.HookTimeout)
+		if testMode {
+			t.record(Termination{Event: e, ExitCode: code})
+			return
+		}
+		cfg.Exit(code)
+		return
+	}
+
+	if e.Level == PANIC {
+		t.flush()
+		v := cfg.PanicValue(e)
+		if testMode {
+			t.record(Termination{Event: e, Panic: true, PanicValue: v})
+			return
+		}
+		panic(v)
+	}
+}
+
+func (t *terminator) record(tr Termination) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.records = append(t.records, tr)
+}
+
+// runHooks calls each hook one by one and returns after all of them are done or after timeout
+func runHooks(e Event, hooks []func(Event), timeout time.Duration) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, h := range hooks {
+			h(e)
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
+
+// SetTerminator sets the way LogProcessor terminates the app after PANIC & FATAL events.
+// Empty fields of t are replaced with defaults.
+func (lp *LogProcessor) SetTerminator(t Terminator) {
+	lp.term.mu.Lock()
+	defer lp.term.mu.Unlock()
+
+	lp.term.cfg = t.withDefaults()
+}
+
+// OnFatal adds hooks that will be called before app exits after logging an event (FATAL by default).
+// Hooks are called one by one in order they were added. Whole list should be done in
+// Terminator.HookTimeout, otherwise app will exit without waiting for the rest.
+//
+// It's the place to flush buffers and close files.
+func (lp *LogProcessor) OnFatal(h ...func(e Event)) {
+	lp.term.mu.Lock()
+	defer lp.term.mu.Unlock()
+
+	lp.term.hooks = append(lp.term.hooks, h...)
+}
+
+// SetTestMode makes LogProcessor record termination instead of actually exiting or panicking.
+// OnFatal hooks are still called. Use Terminations() to get the records.
+func (lp *LogProcessor) SetTestMode(on bool) {
+	lp.term.mu.Lock()
+	defer lp.term.mu.Unlock()
+
+	lp.term.testMode = on
+}
+
+// Terminations returns list of terminations recorded in test mode
+func (lp *LogProcessor) Terminations() []Termination {
+	lp.term.mu.RLock()
+	defer lp.term.mu.RUnlock()
+
+	return append([]Termination(nil), lp.term.records...)
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test mode should record terminations instead of performing them
+func TestLogProcessorTestMode(t *testing.T) {
+	lg1 := &MockLogger{
+		LogType: []LogType{Any},
+	}
+	p := New(false, "", make(chan error), false, lg1)
+	p.SetTestMode(true)
+
+	p.Log(Info("event1"))
+	p.Log(Panic("event2"))
+	p.Log(Fatal("event3"))
+
+	tr := p.Terminations()
+	require.Equal(t, 2, len(tr))
+	assert.Equal(t, true, tr[0].Panic)
+	assert.Equal(t, "event2", tr[0].PanicValue)
+	assert.Equal(t, false, tr[1].Panic)
+	assert.Equal(t, 2, tr[1].ExitCode)
+	assert.Equal(t, "event3", tr[1].Event.Text)
+	assert.Equal(t, "event3", lg1.LoggedData.Text)
+}
+
+// Custom terminator should be used instead of os.Exit & panic(string)
+func TestLogProcessorSetTerminator(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+
+	var code int
+	p.SetTerminator(Terminator{
+		Exit:       func(c int) { code = c },
+		PanicValue: EventPanicValue,
+		ExitCodes:  map[Level]int{FATAL: 3, CRIT: 4},
+	})
+
+	p.Log(Fatal("event1"))
+	assert.Equal(t, 3, code)
+	p.Log(Critical("event2"))
+	assert.Equal(t, 4, code)
+
+	e := Panic("event3").FixTime()
+	assert.PanicsWithValue(t, EventPanic{Event: e}, func() {
+		p.Log(e)
+	})
+}
+
+// OnFatal hooks should run before exit, but not longer than timeout
+func TestLogProcessorOnFatal(t *testing.T) {
+	p := New(false, "", make(chan error), false)
+
+	var calls []string
+	exited := false
+	p.SetTerminator(Terminator{
+		Exit:        func(c int) { exited = true },
+		HookTimeout: 100 * time.Millisecond,
+	})
+	p.OnFatal(
+		func(e Event) { calls = append(calls, "first "+e.Text) },
+		func(e Event) { calls = append(calls, "second "+e.Text) },
+	)
+
+	p.Log(Fatal("event1"))
+	assert.Equal(t, []string{"first event1", "second event1"}, calls)
+	assert.Equal(t, true, exited)
+
+	p.OnFatal(func(e Event) { time.Sleep(time.Second) })
+	start := time.Now()
+	p.Log(Fatal("event2"))
+	assert.Equal(t, true, time.Since(start) < time.Second)
+}
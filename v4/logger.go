@@ -20,6 +20,18 @@ type ILogger interface {
 	Type() []LogType
 }
 
+// TypeMatches returns true in case event of type et should be passed to logger that supports types.
+// Any-typed event matches any logger and logger with Any type receives all events.
+func TypeMatches(et LogType, types []LogType) bool {
+	for _, lt := range types {
+		if et == lt || lt == Any || et == Any {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IFile represents file in the filesystem that's used to log events
 type IFile interface {
 	Write(b []byte) (n int, err error)
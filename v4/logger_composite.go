@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompositeError holds errors returned by loggers inside composite logger
+type CompositeError struct {
+	Errors []error
+}
+
+func (ce CompositeError) Error() string {
+	texts := make([]string, 0, len(ce.Errors))
+	for _, err := range ce.Errors {
+		texts = append(texts, err.Error())
+	}
+
+	return strings.Join(texts, "; ")
+}
+
+// Unwrap returns errors of all loggers
+func (ce CompositeError) Unwrap() []error { return ce.Errors }
+
+// FailoverLogger passes event to loggers one by one until one of them succeeds.
+// E.g. "send to Sentry, and if that fails write to local JSON file".
+//
+// Loggers that do not support event type are skipped, same way LogProcessor does.
+type FailoverLogger struct {
+	loggers []ILogger
+	lTypes  []LogType
+}
+
+// NewFailover returns FailoverLogger that uses la in the specified order
+func NewFailover(la []ILogger, lTypes ...LogType) *FailoverLogger {
+	return &FailoverLogger{loggers: la, lTypes: lTypes}
+}
+
+// Log passes event to loggers until one of them succeeds. Error is returned only if all loggers failed.
+func (l *FailoverLogger) Log(e Event, timeFormat string) error {
+	var errs []error
+	for _, lg := range l.loggers {
+		if !TypeMatches(e.Type, lg.Type()) {
+			continue
+		}
+		err := lg.Log(e, timeFormat)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("[FailoverLogger][Log] all loggers failed: %w", CompositeError{Errors: errs})
+}
+
+// Type returns set of types supported by the logger
+func (l *FailoverLogger) Type() []LogType { return l.lTypes }
+
+// TeeLogger passes event to every logger and treats logging as successful in case
+// at least quorum loggers succeeded. E.g. "write to both A and B, but it's ok if either succeeds".
+//
+// Loggers that do not support event type are skipped and do not count for quorum.
+type TeeLogger struct {
+	loggers []ILogger
+	quorum  int
+	lTypes  []LogType
+}
+
+// NewTee returns TeeLogger that writes events to every logger in la.
+// If quorum <= 0, all loggers that support event type should succeed.
+func NewTee(quorum int, la []ILogger, lTypes ...LogType) *TeeLogger {
+	return &TeeLogger{loggers: la, quorum: quorum, lTypes: lTypes}
+}
+
+// Log passes event to every logger and returns error in case quorum was not reached
+func (l *TeeLogger) Log(e Event, timeFormat string) error {
+	var errs []error
+	var total, ok int
+	for _, lg := range l.loggers {
+		if !TypeMatches(e.Type, lg.Type()) {
+			continue
+		}
+		total++
+		if err := lg.Log(e, timeFormat); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ok++
+	}
+
+	quorum := l.quorum
+	if quorum <= 0 || quorum > total {
+		quorum = total
+	}
+	if ok >= quorum {
+		return nil
+	}
+
+	return fmt.Errorf("[TeeLogger][Log] %d of %d loggers succeeded, %d needed: %w", ok, total, quorum, CompositeError{Errors: errs})
+}
+
+// Type returns set of types supported by the logger
+func (l *TeeLogger) Type() []LogType { return l.lTypes }
+
+// ConditionalLogger passes every event to logger chosen by pick function.
+// In case pick returns nil, event is skipped.
+type ConditionalLogger struct {
+	pick   func(e Event) ILogger
+	lTypes []LogType
+}
+
+// NewConditional returns ConditionalLogger that uses pick to choose logger for every event
+func NewConditional(pick func(e Event) ILogger, lTypes ...LogType) *ConditionalLogger {
+	return &ConditionalLogger{pick: pick, lTypes: lTypes}
+}
+
+// Log passes event to logger returned by pick function
+func (l *ConditionalLogger) Log(e Event, timeFormat string) error {
+	lg := l.pick(e)
+	if lg == nil {
+		return nil
+	}
+	if err := lg.Log(e, timeFormat); err != nil {
+		return fmt.Errorf("[ConditionalLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *ConditionalLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeMatches(t *testing.T) {
+	assert.Equal(t, true, TypeMatches(Main, []LogType{Debug, Main}))
+	assert.Equal(t, true, TypeMatches(Main, []LogType{Any}))
+	assert.Equal(t, true, TypeMatches(Any, []LogType{Debug}))
+	assert.Equal(t, false, TypeMatches(Main, []LogType{Debug}))
+	assert.Equal(t, false, TypeMatches(Main, nil))
+}
+
+// Next logger should be used only in case previous one failed
+func TestLoggerFailoverLog(t *testing.T) {
+	lg1 := &MockLogger{LogType: []LogType{Any}, Err: fmt.Errorf("logger is down")}
+	lg2 := &MockLogger{LogType: []LogType{Any}}
+	lg3 := &MockLogger{LogType: []LogType{Any}}
+
+	fl := NewFailover([]ILogger{lg1, lg2, lg3}, Any)
+	assert.Equal(t, []LogType{Any}, fl.Type())
+
+	require.NoError(t, fl.Log(Info("event1"), time.UnixDate))
+	assert.Equal(t, true, lg1.wasCalledLog)
+	assert.Equal(t, "event1", lg2.LoggedData.Text)
+	assert.Equal(t, false, lg3.wasCalledLog)
+
+	lg2.Err = fmt.Errorf("logger 2 is down")
+	lg3.Err = fmt.Errorf("logger 3 is down")
+	err := fl.Log(Info("event2"), time.UnixDate)
+	require.Error(t, err)
+	var ce CompositeError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, 3, len(ce.Errors))
+}
+
+// Logger errors should be found inside CompositeError by errors.Is & errors.As
+func TestCompositeErrorIsAs(t *testing.T) {
+	errDown := fmt.Errorf("logger is down")
+	ce := CompositeError{Errors: []error{fmt.Errorf("first: %w", ErrCircuitOpen), fmt.Errorf("second: %w", LoggerPanic{Value: "x"}), errDown}}
+
+	assert.ErrorIs(t, ce, ErrCircuitOpen)
+	assert.ErrorIs(t, ce, errDown)
+	assert.NotErrorIs(t, ce, ErrLoggerTimeout)
+
+	var lpanic LoggerPanic
+	require.ErrorAs(t, ce, &lpanic)
+	assert.Equal(t, "x", lpanic.Value)
+	var le LogError
+	assert.False(t, errors.As(ce, &le))
+
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", ce), errDown)
+}
+
+// Tee should succeed in case quorum is reached
+func TestLoggerTeeLog(t *testing.T) {
+	lg1 := &MockLogger{LogType: []LogType{Any}, Err: fmt.Errorf("logger is down")}
+	lg2 := &MockLogger{LogType: []LogType{Any}}
+	lg3 := &MockLogger{LogType: []LogType{Debug}}
+
+	tl := NewTee(1, []ILogger{lg1, lg2, lg3}, Any)
+	require.NoError(t, tl.Log(Info("event1").Main(), time.UnixDate))
+	assert.Equal(t, "event1", lg2.LoggedData.Text)
+	assert.Equal(t, false, lg3.wasCalledLog)
+
+	all := NewTee(0, []ILogger{lg1, lg2, lg3}, Any)
+	assert.Error(t, all.Log(Info("event2").Main(), time.UnixDate))
+	assert.Equal(t, "event2", lg2.LoggedData.Text)
+}
+
+// Conditional should pass event only to logger chosen by pick
+func TestLoggerConditionalLog(t *testing.T) {
+	errLog := &MockLogger{LogType: []LogType{Any}}
+	mainLog := &MockLogger{LogType: []LogType{Any}}
+
+	cl := NewConditional(func(e Event) ILogger {
+		if e.Level.IsError() {
+			return errLog
+		}
+		if e.Level == INFO {
+			return mainLog
+		}
+		return nil
+	}, Any)
+
+	require.NoError(t, cl.Log(Error("event1"), time.UnixDate))
+	require.NoError(t, cl.Log(Info("event2"), time.UnixDate))
+	require.NoError(t, cl.Log(Warning("event3"), time.UnixDate))
+	assert.Equal(t, "event1", errLog.LoggedData.Text)
+	assert.Equal(t, "event2", mainLog.LoggedData.Text)
+}
@@ -0,0 +1,312 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the version of Elastic Common Schema used by ElasticLogger documents
+const ECSVersion = "8.11.0"
+
+// ElasticOptions determines where and how ElasticLogger indexes events
+type ElasticOptions struct {
+	HTTPOptions
+
+	//URL of Elasticsearch or OpenSearch, e.g. http://localhost:9200
+	URL string
+
+	//Index is the name of index (or data stream) with date tokens of FileNameFromTemplate,
+	//date is taken from event time in UTC. Default is lazyevent-{YYYY}.{MM}.{DD}
+	Index string
+
+	//ServiceName & Environment are put into service.name & service.environment fields
+	ServiceName string
+	Environment string
+}
+
+// ElasticItemError is an event that was not indexed
+type ElasticItemError struct {
+	Event  Event
+	Status int
+	Type   string
+	Reason string
+}
+
+// ElasticBulkError is returned in case some events of a bulk request were not indexed
+type ElasticBulkError struct {
+	Failed []ElasticItemError
+	Total  int
+}
+
+func (e *ElasticBulkError) Error() string {
+	if len(e.Failed) == 0 {
+		return "bulk request failed"
+	}
+	f := e.Failed[0]
+
+	return fmt.Sprintf("%d of %d events were not indexed, first error: %d %s: %s", len(e.Failed), e.Total, f.Status, f.Type, f.Reason)
+}
+
+// ElasticLogger indexes events into Elasticsearch or OpenSearch via _bulk API. Documents follow
+// Elastic Common Schema (see ElasticDocument). It implements IBatchLogger: use LogProcessor.SetBatching
+// to index events in bulks. Events rejected because cluster is overloaded (429) are retried
+// with HTTPOptions delays, other rejected events are returned in ElasticBulkError.
+type ElasticLogger struct {
+	lTypes []LogType
+	opts   ElasticOptions
+	base   string
+	host   string
+	sender *httpSender
+}
+
+// NewElastic returns ElasticLogger that indexes events into opts.URL
+func NewElastic(opts ElasticOptions, lTypes ...LogType) (*ElasticLogger, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("[NewElastic] invalid URL %s", opts.URL)
+	}
+	if opts.Index == "" {
+		opts.Index = "lazyevent-{YYYY}.{MM}.{DD}"
+	}
+
+	return &ElasticLogger{
+		lTypes: lTypes,
+		opts:   opts,
+		base:   strings.TrimSuffix(u.String(), "/"),
+		host:   hostName(),
+		sender: newHTTPSender(opts.HTTPOptions),
+	}, nil
+}
+
+// ElasticService is the service.* group of ECS fields
+type ElasticService struct {
+	Name        string `json:"name,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// ElasticDocument is an event in Elastic Common Schema
+type ElasticDocument struct {
+	Timestamp string `json:"@timestamp"`
+	Message   string `json:"message"`
+	Log       struct {
+		Level  string `json:"level,omitempty"`
+		Logger string `json:"logger,omitempty"`
+	} `json:"log"`
+	Event struct {
+		ID       string `json:"id,omitempty"`
+		Severity int    `json:"severity"`
+	} `json:"event"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Service *ElasticService   `json:"service,omitempty"`
+	Host    struct {
+		Hostname string `json:"hostname"`
+	} `json:"host"`
+	ECS struct {
+		Version string `json:"version"`
+	} `json:"ecs"`
+}
+
+// Document returns ECS document of e. Time is always formatted as RFC 3339 for date detection
+func (l *ElasticLogger) Document(e Event) ElasticDocument {
+	var d ElasticDocument
+	d.Timestamp = e.Time.UTC().Format(time.RFC3339Nano)
+	d.Message = e.Text
+	d.Log.Level = strings.ToLower(e.Level.String())
+	d.Log.Logger = e.Source.Text
+	d.Event.ID = e.ID
+	d.Event.Severity = SyslogSeverity(e.Level)
+	d.Labels = map[string]string{"log_type": fmt.Sprint(int(e.Type))}
+	if l.opts.ServiceName != "" || l.opts.Environment != "" {
+		d.Service = &ElasticService{Name: l.opts.ServiceName, Environment: l.opts.Environment}
+	}
+	d.Host.Hostname = l.host
+	d.ECS.Version = ECSVersion
+
+	return d
+}
+
+// IndexName returns index of event made at t
+func (l *ElasticLogger) IndexName(t time.Time) string {
+	return FileNameFromTemplate(l.opts.Index, "", "", t.UTC())
+}
+
+// Log indexes single event. timeFormat is unused: documents have RFC 3339 time
+func (l *ElasticLogger) Log(e Event, timeFormat string) error {
+	if err := l.bulk([]Event{e}); err != nil {
+		return fmt.Errorf("[ElasticLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch indexes events with one bulk request
+func (l *ElasticLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.bulk(events); err != nil {
+		return fmt.Errorf("[ElasticLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+// elasticBulkResponse is the part of _bulk response needed to find failed items
+type elasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func (l *ElasticLogger) bulk(events []Event) error {
+	total := len(events)
+	var failed []ElasticItemError
+	delay := l.sender.opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		body, err := l.BulkBody(events)
+		if err != nil {
+			return err
+		}
+		resp, err := l.sender.send("POST", l.base+"/_bulk", map[string]string{"Content-Type": "application/x-ndjson"}, body)
+		if err != nil {
+			return err
+		}
+
+		var br elasticBulkResponse
+		if err := json.Unmarshal(resp, &br); err != nil {
+			return fmt.Errorf("error parsing bulk response: %w", err)
+		}
+		if !br.Errors {
+			break
+		}
+
+		//Overloaded cluster rejects some items with 429: they are sent again later
+		var retry []Event
+		for i, item := range br.Items {
+			if i >= len(events) {
+				break
+			}
+			for _, res := range item {
+				if res.Error == nil && res.Status < 300 {
+					continue
+				}
+				ie := ElasticItemError{Event: events[i], Status: res.Status}
+				if res.Error != nil {
+					ie.Type, ie.Reason = res.Error.Type, res.Error.Reason
+				}
+				if res.Status == http.StatusTooManyRequests && attempt < l.sender.opts.MaxRetries {
+					retry = append(retry, events[i])
+				} else {
+					failed = append(failed, ie)
+				}
+			}
+		}
+		if len(retry) == 0 {
+			break
+		}
+		events = retry
+		if !l.sender.sleep(delay) {
+			return ErrSenderClosed
+		}
+		if delay *= 2; delay > l.sender.opts.MaxRetryDelay {
+			delay = l.sender.opts.MaxRetryDelay
+		}
+	}
+
+	if len(failed) > 0 {
+		return &ElasticBulkError{Failed: failed, Total: total}
+	}
+
+	return nil
+}
+
+// BulkBody returns NDJSON body of _bulk request that creates documents of events
+func (l *ElasticLogger) BulkBody(events []Event) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, e := range events {
+		//create works both for indices and data streams
+		action := map[string]map[string]string{"create": {"_index": l.IndexName(e.Time)}}
+		if err := enc.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(l.Document(e)); err != nil {
+			return nil, fmt.Errorf("error encoding event: %w", err)
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// IndexTemplate returns composable index template for indices matching pattern (e.g. "lazyevent-*")
+// with mappings of ElasticDocument fields
+func IndexTemplate(pattern string) []byte {
+	keyword := map[string]string{"type": "keyword"}
+	tmpl := map[string]any{
+		"index_patterns": []string{pattern},
+		"priority":       200,
+		"template": map[string]any{
+			"mappings": map[string]any{
+				"dynamic_templates": []any{
+					map[string]any{"labels": map[string]any{
+						"path_match": "labels.*", "mapping": keyword,
+					}},
+				},
+				"properties": map[string]any{
+					"@timestamp": map[string]string{"type": "date"},
+					"message":    map[string]string{"type": "text"},
+					"log": map[string]any{"properties": map[string]any{
+						"level": keyword, "logger": keyword,
+					}},
+					"event": map[string]any{"properties": map[string]any{
+						"id": keyword, "severity": map[string]string{"type": "long"},
+					}},
+					"service": map[string]any{"properties": map[string]any{
+						"name": keyword, "environment": keyword,
+					}},
+					"host": map[string]any{"properties": map[string]any{
+						"hostname": keyword,
+					}},
+					"ecs": map[string]any{"properties": map[string]any{
+						"version": keyword,
+					}},
+				},
+			},
+		},
+	}
+	b, _ := json.Marshal(tmpl)
+
+	return b
+}
+
+// InstallTemplate puts IndexTemplate(pattern) into cluster as index template name
+func (l *ElasticLogger) InstallTemplate(name string, pattern string) error {
+	_, err := l.sender.send("PUT", l.base+"/_index_template/"+url.PathEscape(name),
+		map[string]string{"Content-Type": "application/json"}, IndexTemplate(pattern))
+	if err != nil {
+		return fmt.Errorf("[ElasticLogger][InstallTemplate] %w", err)
+	}
+
+	return nil
+}
+
+// Close interrupts requests & retry delays in progress, they fail with ErrSenderClosed.
+// Logger should not be used after Close
+func (l *ElasticLogger) Close() error {
+	l.sender.close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *ElasticLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elasticStub is a local stand-in of _bulk API. Documents with text in reject are rejected with
+// the status, texts in overload are rejected with 429 once
+type elasticStub struct {
+	t        *testing.T
+	mu       sync.Mutex
+	indexed  map[string][]map[string]any
+	requests int
+	reject   map[string]int
+	overload map[string]bool
+	template []byte
+}
+
+func newElasticStub(t *testing.T) (*elasticStub, string) {
+	s := &elasticStub{t: t, indexed: map[string][]map[string]any{}, reject: map[string]int{}, overload: map[string]bool{}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	return s, srv.URL
+}
+
+func (s *elasticStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(s.t, err)
+	if strings.HasPrefix(r.URL.Path, "/_index_template/") {
+		s.template = body
+		return
+	}
+	require.Equal(s.t, "/_bulk", r.URL.Path)
+	assert.Equal(s.t, "application/x-ndjson", r.Header.Get("Content-Type"))
+	s.requests++
+
+	var items []string
+	hasErrors := false
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		var action map[string]map[string]string
+		require.NoError(s.t, json.Unmarshal(sc.Bytes(), &action))
+		require.True(s.t, sc.Scan())
+		var doc map[string]any
+		require.NoError(s.t, json.Unmarshal(sc.Bytes(), &doc))
+
+		msg := doc["message"].(string)
+		switch {
+		case s.overload[msg]:
+			delete(s.overload, msg)
+			hasErrors = true
+			items = append(items, `{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue is full"}}}`)
+		case s.reject[msg] > 0:
+			hasErrors = true
+			items = append(items, fmt.Sprintf(`{"create":{"status":%d,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}`, s.reject[msg]))
+		default:
+			index := action["create"]["_index"]
+			s.indexed[index] = append(s.indexed[index], doc)
+			items = append(items, `{"create":{"status":201}}`)
+		}
+	}
+	fmt.Fprintf(w, `{"took":1,"errors":%v,"items":[%s]}`, hasErrors, strings.Join(items, ","))
+}
+
+func TestElasticDocument(t *testing.T) {
+	l, err := NewElastic(ElasticOptions{URL: "http://localhost:9200/", ServiceName: "billing", Environment: "prod"}, Any)
+	require.NoError(t, err)
+
+	e := Critical("disk full").Src(EvsMain).SetID("42")
+	e.Time = time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("X", -2*3600))
+	b, err := json.Marshal(l.Document(e))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2026-10-19T01:30:00Z",
+		"message": "disk full",
+		"log": {"level": "critical", "logger": "MAIN"},
+		"event": {"id": "42", "severity": 2},
+		"labels": {"log_type": "0"},
+		"service": {"name": "billing", "environment": "prod"},
+		"host": {"hostname": "`+hostName()+`"},
+		"ecs": {"version": "`+ECSVersion+`"}
+	}`, string(b))
+
+	//Index date is taken in UTC
+	assert.Equal(t, "lazyevent-2026.10.19", l.IndexName(e.Time))
+	_, err = NewElastic(ElasticOptions{URL: "localhost"}, Any)
+	assert.Error(t, err)
+}
+
+// Overloaded items should be retried, other failed items returned
+func TestElasticBulk(t *testing.T) {
+	stub, url := newElasticStub(t)
+	l, err := NewElastic(ElasticOptions{URL: url, Index: "logs-{date}", HTTPOptions: HTTPOptions{MaxRetries: 2}}, Any)
+	require.NoError(t, err)
+	var waits []time.Duration
+	l.sender.sleep = func(d time.Duration) bool { waits = append(waits, d); return true }
+
+	day1 := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	events := []Event{Info("ok1"), Info("busy"), Info("bad"), Info("ok2")}
+	for i := range events {
+		events[i].Time = day1.Add(time.Duration(i) * 6 * time.Hour)
+	}
+	stub.overload["busy"] = true
+	stub.reject["bad"] = 400
+
+	err = l.LogBatch(events, "")
+	var be *ElasticBulkError
+	require.True(t, errors.As(err, &be))
+	assert.Equal(t, 4, be.Total)
+	require.Equal(t, 1, len(be.Failed))
+	assert.Equal(t, "bad", be.Failed[0].Event.Text)
+	assert.Equal(t, 400, be.Failed[0].Status)
+	assert.Equal(t, "mapper_parsing_exception", be.Failed[0].Type)
+
+	assert.Equal(t, 2, stub.requests)
+	assert.Equal(t, []time.Duration{time.Second}, waits)
+	assert.Equal(t, 2, len(stub.indexed["logs-2026-10-18"]))
+	assert.Equal(t, 1, len(stub.indexed["logs-2026-10-19"]))
+
+	//Overloaded items should fail after retries are over
+	stub.overload["busy"] = true
+	l.sender.opts.MaxRetries = 0
+	err = l.Log(events[1], "")
+	require.True(t, errors.As(err, &be))
+	assert.Equal(t, 429, be.Failed[0].Status)
+}
+
+func TestElasticIndexTemplate(t *testing.T) {
+	stub, url := newElasticStub(t)
+	l, err := NewElastic(ElasticOptions{URL: url}, Any)
+	require.NoError(t, err)
+	require.NoError(t, l.InstallTemplate("lazyevent", "lazyevent-*"))
+
+	var tmpl struct {
+		IndexPatterns []string `json:"index_patterns"`
+		Template      struct {
+			Mappings struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"mappings"`
+		} `json:"template"`
+	}
+	require.NoError(t, json.Unmarshal(stub.template, &tmpl))
+	assert.Equal(t, []string{"lazyevent-*"}, tmpl.IndexPatterns)
+
+	//Every document field should be mapped
+	doc, err := json.Marshal(l.Document(Info("x").SetID("1")))
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(doc, &fields))
+	for f := range fields {
+		if f != "labels" {
+			assert.Contains(t, tmpl.Template.Mappings.Properties, f)
+		}
+	}
+	assert.JSONEq(t, `{"type":"date"}`, string(tmpl.Template.Mappings.Properties["@timestamp"]))
+}
@@ -2,98 +2,78 @@ package logger
 
 import (
 	"fmt"
-	"sync"
 	"time"
 )
 
 type CSVFileLogger struct {
-	lTypes         []LogType
-	rotateFiles    bool
-	rotateDuration time.Duration
-	lastLog        time.Time
-	llMutex        *sync.RWMutex
-	filepath       string
-	file           IFile
-	fileMutex      *sync.Mutex
+	lTypes []LogType
+	file   *RotatingFile
 }
 
 // NewCSVtext returns logger capable of creating csv file records.
 //
 // By passing IFile interface as f you can set the initial object to write logs to. Otherwise path & truncate
-// will be used to create new file.
-// Note: if rotateFiles > 0, file will be changed after this period of time any way
+// will be used to create new file. CSV head is written into initial file only if truncate is true.
+// Note: if rotateFiles > 0, file will be changed in case nothing was logged for this number of minutes
 func NewCSVtext(path string, truncate bool, rotateFiles int, f IFile, lTypes ...LogType) (*CSVFileLogger, error) {
-	var err error
-	if f == nil {
-		f, err = makeLogFile(path, truncate, "csv")
-		if err != nil {
-			return nil, fmt.Errorf("[NewCSVtext] %w", err)
-		}
+	rf, err := NewRotatingFile(path, "csv", truncate, f, RotationOptions{
+		RotateAfter: time.Minute * time.Duration(rotateFiles),
+		Header:      []byte(CSVHead),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[NewCSVtext] %w", err)
 	}
 
-	csv := &CSVFileLogger{
-		lTypes:         lTypes,
-		rotateFiles:    rotateFiles > 0,
-		rotateDuration: time.Minute * time.Duration(rotateFiles),
-		lastLog:        time.Now(),
-		file:           f,
-		filepath:       path,
-		llMutex:        &sync.RWMutex{},
-		fileMutex:      &sync.Mutex{},
-	}
+	return &CSVFileLogger{
+		lTypes: lTypes,
+		file:   rf,
+	}, nil
+}
 
-	if truncate {
-		_, err := f.WriteString(CSVHead)
-		if err != nil {
-			return nil, fmt.Errorf("[CSVFileLogger] error making CSV head entry: %w", err)
-		}
+// NewCSVRotating returns logger capable of creating csv file records in files that are changed
+// according to opts. CSV head is written into every new file, opts.Header is ignored.
+func NewCSVRotating(path string, truncate bool, opts RotationOptions, lTypes ...LogType) (*CSVFileLogger, error) {
+	opts.Header = []byte(CSVHead)
+	rf, err := NewRotatingFile(path, "csv", truncate, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[NewCSVRotating] %w", err)
 	}
 
-	return csv, nil
+	return &CSVFileLogger{
+		lTypes: lTypes,
+		file:   rf,
+	}, nil
 }
 
 // Log pushes event data into default output
 func (l *CSVFileLogger) Log(e Event, timeFormat string) error {
-	l.SetLastLog(time.Now())
-	l.fileMutex.Lock()
-	defer l.fileMutex.Unlock()
-	//Make new file in case old one is... old
-	if time.Since(l.LastLog()) > l.rotateDuration && l.rotateFiles {
-		l.file.Close()
-		f, err := makeLogFile(l.filepath, true, "csv")
-		if err != nil {
-			return fmt.Errorf("[CSVFileLogger][Log] %w", err)
-		}
-		l.file = f
-
-		_, err = f.WriteString(CSVHead)
-		if err != nil {
-			return fmt.Errorf("[CSVFileLogger][Log] error making CSV head entry: %w", err)
-		}
-	}
-
-	_, err := l.file.WriteString(FormatCSV(e, timeFormat))
-	if err != nil {
-		return fmt.Errorf("[CSVFileLogger] error making log entry: %w", err)
+	if err := l.file.Write([]byte(FormatCSV(e, timeFormat)), e.Level); err != nil {
+		return fmt.Errorf("[CSVFileLogger][Log] %w", err)
 	}
 
 	return nil
 }
 
+// SetFlushPolicy makes logger buffer records in memory and write them to the file according to p.
+// Zero policy turns buffering off. Buffer is flushed before file is rotated.
+func (l *CSVFileLogger) SetFlushPolicy(p FlushPolicy) error {
+	return l.file.SetFlushPolicy(p)
+}
+
+// Flush writes buffered records to the file
+func (l *CSVFileLogger) Flush() error { return l.file.Flush() }
+
+// Close closes current log file
+func (l *CSVFileLogger) Close() error { return l.file.Close() }
+
+// Reopen closes current log file and opens it again (see RotatingFile.Reopen)
+func (l *CSVFileLogger) Reopen() error { return l.file.Reopen() }
+
 // Type returns set of types supported by the logger
 func (l *CSVFileLogger) Type() []LogType { return l.lTypes }
 
 // LastLog returns last time the logger was used
-func (l *CSVFileLogger) LastLog() time.Time {
-	l.llMutex.RLock()
-	defer l.llMutex.RUnlock()
-
-	return l.lastLog
-}
+func (l *CSVFileLogger) LastLog() time.Time { return l.file.LastWrite() }
 
 // SetLastLog sets last time the logger was used
-func (l *CSVFileLogger) SetLastLog(t time.Time) {
-	l.llMutex.Lock()
-	defer l.llMutex.Unlock()
-	l.lastLog = t
-}
+func (l *CSVFileLogger) SetLastLog(t time.Time) { l.file.SetLastWrite(t) }
@@ -25,7 +25,7 @@ func TestLoggerFileCSVLog(t *testing.T) {
 	require.NoError(t, err)
 
 	now := time.Now()
-	lg1.lastLog = now
+	lg1.SetLastLog(now)
 	time.Sleep(time.Second * 1)
 
 	e := Event{
@@ -40,5 +40,5 @@ func TestLoggerFileCSVLog(t *testing.T) {
 	p.Log(e)
 	assert.Equal(t, CSVHead, m.Text[0])
 	assert.Equal(t, FormatCSV(e, time.UnixDate), m.Text[1]) //time.UnixDate is default here
-	assert.Equal(t, true, lg1.lastLog.After(now))
+	assert.Equal(t, true, lg1.LastLog().After(now))
 }
@@ -2,20 +2,12 @@ package logger
 
 import (
 	"fmt"
-	"sync"
 	"time"
 )
 
 type JSONFileLogger struct {
-	lTypes         []LogType
-	rotateFiles    bool
-	rotateDuration time.Duration
-	lastLog        time.Time
-	llMutex        *sync.RWMutex
-	filepath       string
-	file           IFile
-	fileMutex      *sync.Mutex
-	firstLine      bool
+	lTypes []LogType
+	file   *RotatingFile
 }
 
 // NewJSONtext returns logger capable of creating json-encoded records in text file.
@@ -25,78 +17,70 @@ type JSONFileLogger struct {
 //
 // By passing IFile interface as f you can set the initial object to write logs to. Otherwise path & truncate
 // will be used to create new file.
-// Note: if rotateFiles > 0, file will be changed after this period of time any way
+// Note: if rotateFiles > 0, file will be changed in case nothing was logged for this number of minutes
 func NewJSONtext(path string, truncate bool, rotateFiles int, f IFile, lTypes ...LogType) (*JSONFileLogger, error) {
-	var err error
-	if f == nil {
-		f, err = makeLogFile(path, truncate, "json")
-		if err != nil {
-			return nil, fmt.Errorf("[NewJSONtext] %w", err)
-		}
+	rf, err := NewRotatingFile(path, "json", truncate, f, RotationOptions{
+		RotateAfter: time.Minute * time.Duration(rotateFiles),
+		Separator:   []byte(",\n"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[NewJSONtext] %w", err)
 	}
 
 	return &JSONFileLogger{
-		lTypes:         lTypes,
-		rotateFiles:    rotateFiles > 0,
-		lastLog:        time.Now(),
-		rotateDuration: time.Minute * time.Duration(rotateFiles),
-		filepath:       path,
-		file:           f,
-		llMutex:        &sync.RWMutex{},
-		fileMutex:      &sync.Mutex{},
-		firstLine:      true,
+		lTypes: lTypes,
+		file:   rf,
 	}, nil
 }
 
-// Log pushes event data into default output
-func (l *JSONFileLogger) Log(e Event, timeFormat string) error {
-	l.SetLastLog(time.Now())
-	l.fileMutex.Lock()
-	defer l.fileMutex.Unlock()
-	//Make new file in case old one is... old
-	if time.Since(l.LastLog()) > l.rotateDuration && l.rotateFiles {
-		l.file.Close()
-		f, err := makeLogFile(l.filepath, true, "json")
-		if err != nil {
-			return fmt.Errorf("[JSONFileLogger][Log] %w", err)
-		}
-		l.file = f
-		l.firstLine = true
+// NewJSONRotating returns logger capable of creating json-encoded records in files that are changed
+// according to opts. Records are separated by ",\n", opts.Separator is ignored.
+func NewJSONRotating(path string, truncate bool, opts RotationOptions, lTypes ...LogType) (*JSONFileLogger, error) {
+	opts.Separator = []byte(",\n")
+	rf, err := NewRotatingFile(path, "json", truncate, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[NewJSONRotating] %w", err)
 	}
 
-	var text []byte
-	if !l.firstLine {
-		text = append(text, ',', '\n')
-	}
+	return &JSONFileLogger{
+		lTypes: lTypes,
+		file:   rf,
+	}, nil
+}
 
+// Log pushes event data into default output
+func (l *JSONFileLogger) Log(e Event, timeFormat string) error {
 	js, err := FormatJSON(e, timeFormat)
 	if err != nil {
 		return fmt.Errorf("[JSONFileLogger] error formatting event to JSON: %w", err)
 	}
-	text = append(text, js...)
-	_, err = l.file.Write(text)
-	if err != nil {
-		return fmt.Errorf("[JSONFileLogger] error making log entry: %w", err)
+	if err := l.file.Write(js, e.Level); err != nil {
+		return fmt.Errorf("[JSONFileLogger][Log] %w", err)
 	}
-	l.firstLine = false
 
 	return nil
 }
 
+// SetFlushPolicy makes logger buffer records in memory and write them to the file according to p.
+// Zero policy turns buffering off. Buffer is flushed before file is rotated.
+func (l *JSONFileLogger) SetFlushPolicy(p FlushPolicy) error {
+	return l.file.SetFlushPolicy(p)
+}
+
+// Flush writes buffered records to the file
+func (l *JSONFileLogger) Flush() error { return l.file.Flush() }
+
+// Close closes current log file
+func (l *JSONFileLogger) Close() error { return l.file.Close() }
+
+// Reopen closes current log file and opens it again (see RotatingFile.Reopen)
+func (l *JSONFileLogger) Reopen() error { return l.file.Reopen() }
+
 // Type returns set of types supported by the logger
 func (l *JSONFileLogger) Type() []LogType { return l.lTypes }
 
 // LastLog returns last time the logger was used
-func (l *JSONFileLogger) LastLog() time.Time {
-	l.llMutex.RLock()
-	defer l.llMutex.RUnlock()
-
-	return l.lastLog
-}
+func (l *JSONFileLogger) LastLog() time.Time { return l.file.LastWrite() }
 
 // SetLastLog sets last time the logger was used
-func (l *JSONFileLogger) SetLastLog(t time.Time) {
-	l.llMutex.Lock()
-	defer l.llMutex.Unlock()
-	l.lastLog = t
-}
+func (l *JSONFileLogger) SetLastLog(t time.Time) { l.file.SetLastWrite(t) }
@@ -25,7 +25,7 @@ func TestLoggerFileJSONLog(t *testing.T) {
 	require.NoError(t, err)
 
 	now := time.Now()
-	lg1.lastLog = now
+	lg1.SetLastLog(now)
 	time.Sleep(time.Second * 1)
 
 	e := Event{
@@ -43,5 +43,5 @@ func TestLoggerFileJSONLog(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, string(result), m.Text[0])
-	assert.Equal(t, true, lg1.lastLog.After(now))
+	assert.Equal(t, true, lg1.LastLog().After(now))
 }
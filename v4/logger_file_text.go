@@ -2,93 +2,86 @@ package logger
 
 import (
 	"fmt"
-	"sync"
 	"time"
 )
 
 type PlaintextFileLogger struct {
 	//pureText means logger will print out only log text itself
-	pureText       bool
-	rotateFiles    bool
-	rotateDuration time.Duration
-	lastLog        time.Time
-	llMutex        *sync.RWMutex
-	lTypes         []LogType
-	filepath       string
-	file           IFile
-	fileMutex      *sync.Mutex
+	pureText bool
+	lTypes   []LogType
+	file     *RotatingFile
 }
 
 // NewPlaintext returns logger capable of appending strings to text file.
 //
 // By passing IFile interface as f you can set the initial object to write logs to. Otherwise path & truncate
 // will be used to create new file.
-// Note: if rotateFiles > 0, file will be changed after this period of time any way
+// Note: if rotateFiles > 0, file will be changed in case nothing was logged for this number of minutes
 func NewPlaintext(path string, pureText bool, truncate bool, rotateFiles int, f IFile, lTypes ...LogType) (*PlaintextFileLogger, error) {
-	var err error
-	if f == nil {
-		f, err = makeLogFile(path, truncate, "log")
-		if err != nil {
-			return nil, fmt.Errorf("[NewPlaintext] %w", err)
-		}
+	rf, err := NewRotatingFile(path, "log", truncate, f, RotationOptions{
+		RotateAfter: time.Minute * time.Duration(rotateFiles),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[NewPlaintext] %w", err)
 	}
 
 	return &PlaintextFileLogger{
-		pureText:       pureText,
-		rotateFiles:    rotateFiles > 0,
-		rotateDuration: time.Minute * time.Duration(rotateFiles),
-		lastLog:        time.Now(),
-		lTypes:         lTypes,
-		filepath:       path,
-		file:           f,
-		llMutex:        &sync.RWMutex{},
-		fileMutex:      &sync.Mutex{},
+		pureText: pureText,
+		lTypes:   lTypes,
+		file:     rf,
 	}, nil
 }
 
-// Log pushes event data into default output
-func (l *PlaintextFileLogger) Log(e Event, timeFormat string) error {
-	l.SetLastLog(time.Now())
-	l.fileMutex.Lock()
-	defer l.fileMutex.Unlock()
-	//Make new file in case old one is... old
-	if time.Since(l.LastLog()) > l.rotateDuration && l.rotateFiles {
-		l.file.Close()
-		f, err := makeLogFile(l.filepath, true, "log")
-		if err != nil {
-			return fmt.Errorf("[PlaintextFileLogger][Log] %w", err)
-		}
-		l.file = f
+// NewPlaintextRotating returns logger capable of appending strings to text files that are changed
+// according to opts (e.g. daily at midnight with names like app-2026-10-18.log).
+func NewPlaintextRotating(path string, pureText bool, truncate bool, opts RotationOptions, lTypes ...LogType) (*PlaintextFileLogger, error) {
+	rf, err := NewRotatingFile(path, "log", truncate, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("[NewPlaintextRotating] %w", err)
 	}
 
+	return &PlaintextFileLogger{
+		pureText: pureText,
+		lTypes:   lTypes,
+		file:     rf,
+	}, nil
+}
+
+// Log pushes event data into default output
+func (l *PlaintextFileLogger) Log(e Event, timeFormat string) error {
 	log := ""
 	if l.pureText {
 		log = FormatOutputPureText(e)
 	} else {
 		log = FormatOutput(e, timeFormat)
 	}
-	_, err := l.file.WriteString(log)
-	if err != nil {
-		return err
+	if err := l.file.Write([]byte(log), e.Level); err != nil {
+		return fmt.Errorf("[PlaintextFileLogger][Log] %w", err)
 	}
 
 	return nil
 }
 
+// SetFlushPolicy makes logger buffer records in memory and write them to the file according to p.
+// Zero policy turns buffering off. Buffer is flushed before file is rotated.
+func (l *PlaintextFileLogger) SetFlushPolicy(p FlushPolicy) error {
+	return l.file.SetFlushPolicy(p)
+}
+
+// Flush writes buffered records to the file
+func (l *PlaintextFileLogger) Flush() error { return l.file.Flush() }
+
+// Close closes current log file
+func (l *PlaintextFileLogger) Close() error { return l.file.Close() }
+
+// Reopen closes current log file and opens it again (see RotatingFile.Reopen)
+func (l *PlaintextFileLogger) Reopen() error { return l.file.Reopen() }
+
 // Type returns set of types supported by the logger
 func (l *PlaintextFileLogger) Type() []LogType { return l.lTypes }
 
 // LastLog returns last time the logger was used
-func (l *PlaintextFileLogger) LastLog() time.Time {
-	l.llMutex.RLock()
-	defer l.llMutex.RUnlock()
-
-	return l.lastLog
-}
+func (l *PlaintextFileLogger) LastLog() time.Time { return l.file.LastWrite() }
 
 // SetLastLog sets last time the logger was used
-func (l *PlaintextFileLogger) SetLastLog(t time.Time) {
-	l.llMutex.Lock()
-	defer l.llMutex.Unlock()
-	l.lastLog = t
-}
+func (l *PlaintextFileLogger) SetLastLog(t time.Time) { l.file.SetLastWrite(t) }
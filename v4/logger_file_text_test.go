@@ -29,8 +29,8 @@ func TestLoggerFileTextLog(t *testing.T) {
 	require.NoError(t, err)
 
 	now := time.Now()
-	lg1.lastLog = now
-	lg2.lastLog = now
+	lg1.SetLastLog(now)
+	lg2.SetLastLog(now)
 	time.Sleep(time.Second * 1)
 
 	e := Event{
@@ -45,6 +45,6 @@ func TestLoggerFileTextLog(t *testing.T) {
 	p.Log(e)
 	assert.Equal(t, FormatOutput(e, time.UnixDate), m.Text[0]) //time.UnixDate is default here
 	assert.Equal(t, FormatOutputPureText(e), m2.Text[0])
-	assert.Equal(t, true, lg1.lastLog.After(now))
-	assert.Equal(t, true, lg2.lastLog.After(now))
+	assert.Equal(t, true, lg1.LastLog().After(now))
+	assert.Equal(t, true, lg2.LastLog().After(now))
 }
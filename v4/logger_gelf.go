@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GELFCompression determines how GELFLogger compresses UDP messages
+type GELFCompression int
+
+const (
+	GELFGzip GELFCompression = iota
+	GELFZlib
+	GELFNoCompression
+)
+
+// GELF chunking limits: Graylog accepts at most 128 chunks of one message
+const (
+	GELFChunkSize = 1420
+	gelfMaxChunks = 128
+)
+
+// ErrGELFTooLarge is returned in case message does not fit into 128 UDP chunks
+var ErrGELFTooLarge = fmt.Errorf("message is larger than %d GELF chunks", gelfMaxChunks)
+
+// GELFOptions determines where and how GELFLogger sends messages
+type GELFOptions struct {
+	//Network is "udp" (default) or "tcp"
+	Network string
+
+	//Addr is host:port of Graylog input
+	Addr string
+
+	//Compression of UDP messages, gzip by default. TCP messages are not compressed
+	Compression GELFCompression
+
+	//ChunkSize is the max size of UDP datagram, larger messages are chunked. Default is GELFChunkSize
+	ChunkSize int
+
+	//Host is the host field of messages. Default is host name
+	Host string
+
+	//Fields are added to every message as additional fields (e.g. {"app": "billing"}), _ prefix is added
+	Fields map[string]any
+
+	//Timeout limits connecting & writing. Default is 5 seconds
+	Timeout time.Duration
+}
+
+// GELFLogger sends events to Graylog in GELF 1.1 format over UDP (with chunking & compression)
+// or TCP (null-byte delimited). TCP connection is re-established in case write fails.
+type GELFLogger struct {
+	lTypes []LogType
+	opts   GELFOptions
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewGELF returns GELFLogger connected to opts.Addr
+func NewGELF(opts GELFOptions, lTypes ...LogType) (*GELFLogger, error) {
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Network != "udp" && opts.Network != "tcp" {
+		return nil, fmt.Errorf("[NewGELF] unsupported network %s", opts.Network)
+	}
+	if opts.ChunkSize <= 12 {
+		opts.ChunkSize = GELFChunkSize
+	}
+	if opts.Host == "" {
+		opts.Host = hostName()
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	l := &GELFLogger{lTypes: lTypes, opts: opts}
+	if err := l.connect(); err != nil {
+		return nil, fmt.Errorf("[NewGELF] %w", err)
+	}
+
+	return l, nil
+}
+
+func (l *GELFLogger) connect() error {
+	conn, err := net.DialTimeout(l.opts.Network, l.opts.Addr, l.opts.Timeout)
+	if err != nil {
+		return fmt.Errorf("can not connect to Graylog: %w", err)
+	}
+	l.conn = conn
+
+	return nil
+}
+
+// Log sends event to Graylog. timeFormat is unused: GELF timestamp is unix time
+func (l *GELFLogger) Log(e Event, timeFormat string) error {
+	msg, err := l.Message(e)
+	if err != nil {
+		return fmt.Errorf("[GELFLogger][Log] %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.opts.Network == "udp" {
+		err = l.sendUDP(msg)
+	} else {
+		err = l.sendTCP(append(msg, 0))
+	}
+	if err != nil {
+		return fmt.Errorf("[GELFLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+func (l *GELFLogger) sendUDP(msg []byte) error {
+	if l.conn == nil {
+		return net.ErrClosed
+	}
+	msg, err := l.compress(msg)
+	if err != nil {
+		return err
+	}
+	chunks, err := gelfChunks(msg, l.opts.ChunkSize)
+	if err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		l.conn.SetWriteDeadline(time.Now().Add(l.opts.Timeout))
+		if _, err := l.conn.Write(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *GELFLogger) sendTCP(msg []byte) error {
+	//Retry once with new connection: server may have been restarted
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if l.conn == nil {
+			if err = l.connect(); err != nil {
+				continue
+			}
+		}
+		l.conn.SetWriteDeadline(time.Now().Add(l.opts.Timeout))
+		if _, err = l.conn.Write(msg); err == nil {
+			return nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	return err
+}
+
+func (l *GELFLogger) compress(msg []byte) ([]byte, error) {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch l.opts.Compression {
+	case GELFGzip:
+		w = gzip.NewWriter(&b)
+	case GELFZlib:
+		w = zlib.NewWriter(&b)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// gelfChunks splits msg into GELF chunks of size at most size (including 12-byte chunk header).
+// Message that fits into one datagram is returned as is
+func gelfChunks(msg []byte, size int) ([][]byte, error) {
+	if len(msg) <= size {
+		return [][]byte{msg}, nil
+	}
+	data := size - 12
+	count := (len(msg) + data - 1) / data
+	if count > gelfMaxChunks {
+		return nil, ErrGELFTooLarge
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * data
+		if end > len(msg) {
+			end = len(msg)
+		}
+		c := make([]byte, 0, 12+end-i*data)
+		c = append(c, 0x1e, 0x0f)
+		c = append(c, id...)
+		c = append(c, byte(i), byte(count))
+		c = append(c, msg[i*data:end]...)
+		chunks = append(chunks, c)
+	}
+
+	return chunks, nil
+}
+
+// Message returns GELF 1.1 JSON message of e. First line of text is the short message,
+// whole text is the full message in case it has several lines
+func (l *GELFLogger) Message(e Event) ([]byte, error) {
+	msg := map[string]any{}
+	for k, v := range l.opts.Fields {
+		msg[gelfFieldName(k)] = v
+	}
+
+	short, _, multiline := strings.Cut(e.Text, "\n")
+	msg["version"] = "1.1"
+	msg["host"] = l.opts.Host
+	msg["short_message"] = short
+	if multiline {
+		msg["full_message"] = e.Text
+	}
+	if short == "" {
+		//short_message is required to be non-empty
+		msg["short_message"] = "-"
+	}
+	msg["timestamp"] = json.Number(strconv.FormatFloat(float64(e.Time.UnixMicro())/1e6, 'f', 6, 64))
+	msg["level"] = SyslogSeverity(e.Level)
+	if e.ID != "" {
+		msg["_event_id"] = e.ID
+	}
+	if e.Source.Text != "" {
+		//_source would become "source" in Graylog, which is already taken by host name
+		msg["_event_source"] = e.Source.Text
+	}
+	if lvl := e.Level.String(); lvl != "" {
+		msg["_level_name"] = lvl
+	}
+	msg["_log_type"] = int(e.Type)
+
+	return json.Marshal(msg)
+}
+
+// gelfFieldName returns additional field name: _ prefixed, with characters other than
+// letters, digits, _, - and . replaced by _. Field _id is reserved and _source would clash with
+// Graylog's source, so they become __id & __source
+func gelfFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if !strings.HasPrefix(name, "_") {
+		name = "_" + name
+	}
+	if name == "_id" || name == "_source" {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// Close closes connection to Graylog
+func (l *GELFLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+
+	return err
+}
+
+// Type returns set of types supported by the logger
+func (l *GELFLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readGELFUDP reads datagrams until full message is assembled and decompresses it like Graylog does
+func readGELFUDP(t *testing.T, conn net.PacketConn) (map[string]any, int) {
+	buf := make([]byte, 65536)
+	chunks := map[byte][]byte{}
+	datagrams := 0
+	var msg []byte
+	for msg == nil {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		datagrams++
+		d := append([]byte{}, buf[:n]...)
+		if !bytes.HasPrefix(d, []byte{0x1e, 0x0f}) {
+			msg = d
+			break
+		}
+		chunks[d[10]] = d[12:]
+		if count := int(d[11]); len(chunks) == count {
+			for i := 0; i < count; i++ {
+				msg = append(msg, chunks[byte(i)]...)
+			}
+		}
+	}
+
+	var r io.Reader = bytes.NewReader(msg)
+	var err error
+	switch {
+	case bytes.HasPrefix(msg, []byte{0x1f, 0x8b}):
+		r, err = gzip.NewReader(r)
+	case msg[0] == 0x78:
+		r, err = zlib.NewReader(r)
+	}
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.NewDecoder(r).Decode(&m))
+
+	return m, datagrams
+}
+
+func TestGELFMessage(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	l, err := NewGELF(GELFOptions{
+		Addr:        conn.LocalAddr().String(),
+		Host:        "web1",
+		Compression: GELFNoCompression,
+		Fields:      map[string]any{"app": "billing", "id": 5, "source": "cfg", "bad key": true},
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	e := Error("first line\nsecond line").Src(EvsMain).SetID("42")
+	e.Time = time.Date(2026, 10, 18, 12, 0, 0, 123456000, time.UTC)
+	require.NoError(t, l.Log(e, ""))
+
+	m, datagrams := readGELFUDP(t, conn)
+	assert.Equal(t, 1, datagrams)
+	assert.Equal(t, map[string]any{
+		"version":       "1.1",
+		"host":          "web1",
+		"short_message": "first line",
+		"full_message":  "first line\nsecond line",
+		"timestamp":     1792324800.123456,
+		"level":         float64(3),
+		"_event_id":     "42",
+		"_event_source": "MAIN",
+		"_level_name":   "ERROR",
+		"_log_type":     float64(0),
+		"_app":          "billing",
+		"__id":          float64(5),
+		"__source":      "cfg",
+		"_bad_key":      true,
+	}, m)
+}
+
+// Large messages should be compressed & chunked
+func TestGELFChunking(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	//Random-like text is hard to compress, so it needs several chunks
+	var text strings.Builder
+	for i := 0; text.Len() < 20000; i++ {
+		text.WriteString(time.Duration(i * 7919 * 104729).String())
+	}
+
+	for _, c := range []GELFCompression{GELFGzip, GELFZlib, GELFNoCompression} {
+		l, err := NewGELF(GELFOptions{Addr: conn.LocalAddr().String(), Compression: c, ChunkSize: 1000}, Any)
+		require.NoError(t, err)
+		require.NoError(t, l.Log(Info(text.String()), ""))
+		m, datagrams := readGELFUDP(t, conn)
+		assert.Equal(t, text.String(), m["short_message"])
+		assert.True(t, datagrams > 1, "compression %d", c)
+		l.Close()
+	}
+
+	_, err = gelfChunks(make([]byte, 129*100), 112)
+	assert.True(t, errors.Is(err, ErrGELFTooLarge))
+	chunks, err := gelfChunks(make([]byte, 128*100), 112)
+	require.NoError(t, err)
+	assert.Equal(t, 128, len(chunks))
+}
+
+// TCP messages should be null-terminated and connection re-established after failure
+func TestGELFTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	l, err := NewGELF(GELFOptions{Network: "tcp", Addr: ln.Addr().String()}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(Info("event1"), ""))
+	require.NoError(t, l.Log(Warning("event2"), ""))
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"event1", "event2"} {
+		b, err := r.ReadBytes(0)
+		require.NoError(t, err)
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(b[:len(b)-1], &m))
+		assert.Equal(t, want, m["short_message"])
+	}
+
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		if err := l.Log(Info("after"), ""); err != nil {
+			return false
+		}
+		ln.(*net.TCPListener).SetDeadline(time.Now().Add(50 * time.Millisecond))
+		conn, err = ln.Accept()
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer conn.Close()
+	b, err := bufio.NewReader(conn).ReadBytes(0)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"short_message":"after"`)
+}
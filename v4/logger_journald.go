@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JournaldSocket is the default path of journald native protocol socket
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldOptions determines how JournaldLogger maps events to journal fields
+type JournaldOptions struct {
+	//Socket is the journald socket path. Default is JournaldSocket
+	Socket string
+
+	//Identifier is SYSLOG_IDENTIFIER of events without source. Default is program name
+	Identifier string
+
+	//SourceField is the field for event source. Default is SYSLOG_IDENTIFIER,
+	//so journalctl -t can filter by source
+	SourceField string
+
+	//IDField is the field for event ID. Default is EVENT_ID. Journal MESSAGE_ID is
+	//meant to identify kind of message (for catalogs), not a single record, so it's not used
+	IDField string
+
+	//Fields are added to every entry (e.g. {"APP_VERSION": "1.2.0"}).
+	//Names are uppercased, characters other than A-Z, 0-9 and _ are replaced by _
+	Fields map[string]string
+}
+
+// JournaldLogger sends events to systemd-journald via its native protocol: every event is a single
+// datagram with journal fields. Events too large for a datagram are passed via sealed memfd (Linux only).
+type JournaldLogger struct {
+	lTypes []LogType
+	opts   JournaldOptions
+	static []byte
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournald returns JournaldLogger connected to journald socket
+func NewJournald(opts JournaldOptions, lTypes ...LogType) (*JournaldLogger, error) {
+	if opts.Socket == "" {
+		opts.Socket = JournaldSocket
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	opts.SourceField = journalFieldName(opts.SourceField)
+	if opts.SourceField == "" {
+		opts.SourceField = "SYSLOG_IDENTIFIER"
+	}
+	opts.IDField = journalFieldName(opts.IDField)
+	if opts.IDField == "" {
+		opts.IDField = "EVENT_ID"
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: opts.Socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("[NewJournald] can not connect to journald: %w", err)
+	}
+
+	//Static fields are the same for all entries, so they are encoded once
+	var static bytes.Buffer
+	names := make([]string, 0, len(opts.Fields))
+	for name := range opts.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		appendJournalField(&static, journalFieldName(name), opts.Fields[name])
+	}
+
+	return &JournaldLogger{lTypes: lTypes, opts: opts, static: static.Bytes(), conn: conn}, nil
+}
+
+// Log sends event to journald. timeFormat is unused: journal stores its own timestamps
+func (l *JournaldLogger) Log(e Event, timeFormat string) error {
+	msg := l.Format(e)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.conn.Write(msg)
+	if err != nil && journalTooLarge(err) {
+		err = sendJournalMemfd(l.conn, msg)
+	}
+	if err != nil {
+		return fmt.Errorf("[JournaldLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// Format returns journal entry of e encoded by native protocol
+func (l *JournaldLogger) Format(e Event) []byte {
+	var b bytes.Buffer
+	appendJournalField(&b, "MESSAGE", e.Text)
+	appendJournalField(&b, "PRIORITY", strconv.Itoa(SyslogSeverity(e.Level)))
+
+	source := e.Source.Text
+	if l.opts.SourceField != "SYSLOG_IDENTIFIER" || source == "" {
+		appendJournalField(&b, "SYSLOG_IDENTIFIER", l.opts.Identifier)
+	}
+	if source != "" {
+		appendJournalField(&b, l.opts.SourceField, source)
+	}
+	if e.ID != "" {
+		appendJournalField(&b, l.opts.IDField, e.ID)
+	}
+	if lvl := e.Level.String(); lvl != "" {
+		appendJournalField(&b, "EVENT_LEVEL", lvl)
+	}
+	appendJournalField(&b, "EVENT_TYPE", strconv.Itoa(int(e.Type)))
+	b.Write(l.static)
+
+	return b.Bytes()
+}
+
+// appendJournalField encodes field: as NAME=value line or, in case value has line breaks,
+// as NAME line followed by 64-bit little endian value size, value & line break
+func appendJournalField(b *bytes.Buffer, name string, val string) {
+	if !strings.Contains(val, "\n") {
+		b.WriteString(name + "=" + val + "\n")
+		return
+	}
+
+	b.WriteString(name + "\n")
+	binary.Write(b, binary.LittleEndian, uint64(len(val)))
+	b.WriteString(val + "\n")
+}
+
+// journalFieldName returns name suitable for journal field
+func journalFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, name)
+
+	//Fields starting with _ are trusted fields set by journald itself
+	return strings.TrimLeft(name, "_")
+}
+
+// Close closes connection to journald
+func (l *JournaldLogger) Close() error {
+	return l.conn.Close()
+}
+
+// Type returns set of types supported by the logger
+func (l *JournaldLogger) Type() []LogType { return l.lTypes }
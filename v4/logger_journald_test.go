@@ -0,0 +1,145 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJournal listens on unix datagram socket like journald does
+func fakeJournal(t *testing.T) (*net.UnixConn, string) {
+	//Socket path should be short, so temp dir of the test may not fit
+	dir, err := os.MkdirTemp("", "journal")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	sock := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, sock
+}
+
+// readJournalEntry reads one entry from the socket, taking it from passed memfd in case there is one
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf := make([]byte, 1<<20)
+	oob := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		require.NoError(t, err)
+		require.Equal(t, 1, len(msgs))
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		require.NoError(t, err)
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer f.Close()
+		//journald reads memfd from the start
+		_, err = f.Seek(0, 0)
+		require.NoError(t, err)
+		var mem bytes.Buffer
+		_, err = mem.ReadFrom(f)
+		require.NoError(t, err)
+		data = mem.Bytes()
+	}
+
+	return parseJournalEntry(t, data)
+}
+
+func parseJournalEntry(t *testing.T, data []byte) map[string]string {
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		require.True(t, i >= 0)
+		line := string(data[:i])
+		data = data[i+1:]
+		if name, val, ok := strings.Cut(line, "="); ok {
+			fields[name] = val
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[:8])
+		fields[line] = string(data[8 : 8+size])
+		require.Equal(t, byte('\n'), data[8+size])
+		data = data[9+size:]
+	}
+
+	return fields
+}
+
+func TestJournaldFields(t *testing.T) {
+	conn, sock := fakeJournal(t)
+	l, err := NewJournald(JournaldOptions{
+		Socket:     sock,
+		Identifier: "myapp",
+		Fields:     map[string]string{"app.version": "1.2.0", "_PID": "1"},
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.Log(Error("line1\nline2").Src(EvsMain).SetID("42"), ""))
+	assert.Equal(t, map[string]string{
+		"MESSAGE":           "line1\nline2",
+		"PRIORITY":          "3",
+		"SYSLOG_IDENTIFIER": "MAIN",
+		"EVENT_ID":          "42",
+		"EVENT_LEVEL":       "ERROR",
+		"EVENT_TYPE":        "0",
+		"APP_VERSION":       "1.2.0",
+		"PID":               "1",
+	}, readJournalEntry(t, conn))
+
+	require.NoError(t, l.Log(Info("no source"), ""))
+	e := readJournalEntry(t, conn)
+	assert.Equal(t, "myapp", e["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "6", e["PRIORITY"])
+	_, ok := e["EVENT_ID"]
+	assert.False(t, ok)
+}
+
+// Source can be put into custom field keeping app identifier
+func TestJournaldSourceField(t *testing.T) {
+	conn, sock := fakeJournal(t)
+	l, err := NewJournald(JournaldOptions{
+		Socket:      sock,
+		Identifier:  "myapp",
+		SourceField: "code_source",
+		IDField:     "REQUEST_ID",
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.Log(Fatal("event").Src(EvsDebug).SetID("7"), ""))
+	e := readJournalEntry(t, conn)
+	assert.Equal(t, "myapp", e["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "DEBUG", e["CODE_SOURCE"])
+	assert.Equal(t, "7", e["REQUEST_ID"])
+	assert.Equal(t, "0", e["PRIORITY"])
+}
+
+// Entries larger than datagram limit should be passed via memfd
+func TestJournaldMemfd(t *testing.T) {
+	conn, sock := fakeJournal(t)
+	require.NoError(t, conn.SetReadBuffer(4<<20))
+	l, err := NewJournald(JournaldOptions{Socket: sock}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	text := strings.Repeat("x", 512<<10)
+	require.NoError(t, l.Log(Info(text), ""))
+	assert.Equal(t, text, readJournalEntry(t, conn)["MESSAGE"])
+}
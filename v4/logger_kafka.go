@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ErrInvalidTopic is returned for events with topic that can not be produced to
+// (empty, "." or "..", longer than 249 characters or with characters other than a-z, A-Z, 0-9, '.', '_' and '-')
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// kafkaMaxTopic is the maximum length of topic name
+const kafkaMaxTopic = 249
+
+// KafkaAcks determines which acknowledgement KafkaLogger waits for
+type KafkaAcks int
+
+const (
+	//KafkaAckAll waits for all in-sync replicas
+	KafkaAckAll KafkaAcks = iota
+
+	//KafkaAckLeader waits for partition leader only
+	KafkaAckLeader
+
+	//KafkaAckNone does not wait: broker does not respond and errors are not known
+	KafkaAckNone
+)
+
+// kgo returns acks option of the client
+func (a KafkaAcks) kgo() kgo.Acks {
+	switch a {
+	case KafkaAckLeader:
+		return kgo.LeaderAck()
+	case KafkaAckNone:
+		return kgo.NoAck()
+	default:
+		return kgo.AllISRAcks()
+	}
+}
+
+// KafkaOptions determines where and how KafkaLogger produces events
+type KafkaOptions struct {
+	//Brokers are host:port of bootstrap brokers, other brokers are found via metadata
+	Brokers []string
+
+	//Topic is the topic template with EventRoute tokens, e.g. "logs-{source}". Default is "lazyevent"
+	Topic string
+
+	//TopicFunc returns topic of event instead of Topic template. Events with invalid
+	//topics fail with ErrInvalidTopic
+	TopicFunc func(e Event) string
+
+	//AutoCreateTopics lets brokers create topics that do not exist (in case brokers allow it).
+	//It's off by default: with "{source}" in Topic every new source would silently create a topic
+	AutoCreateTopics bool
+
+	//Key is the partition key template with EventRoute tokens, e.g. "{source}". Events with the same key
+	//get the same partition (murmur2, as Java clients do). Events without key stick to one of partitions
+	//that have a leader until a batch is sent, then move to another one
+	Key string
+
+	//KeyFunc returns key of event instead of Key template. Nil key means no key
+	KeyFunc func(e Event) []byte
+
+	//Format makes message value of event. Default is FormatJSON
+	Format func(e Event, timeFormat string) ([]byte, error)
+
+	//Acks is the acknowledgement to wait for, all in-sync replicas by default
+	Acks KafkaAcks
+
+	//ClientID is sent to brokers, default is "lazyevent"
+	ClientID string
+
+	//TLSConfig turns TLS on
+	TLSConfig *tls.Config
+
+	//Timeout limits connecting & delivery of every event, including retries. Default is 10 seconds
+	Timeout time.Duration
+}
+
+// KafkaLogger produces events to Kafka topics via franz-go client. It implements IBatchLogger:
+// with batching on (see LogProcessor.SetBatching) events of a batch are produced at once, the client
+// groups them into record batches by partition and writes to partition leaders concurrently.
+// Retriable failures (e.g. leadership changes) are retried by the client until Timeout.
+type KafkaLogger struct {
+	lTypes []LogType
+	opts   KafkaOptions
+	client *kgo.Client
+}
+
+// NewKafka returns KafkaLogger that produces events to opts.Brokers cluster.
+// Brokers are connected on first event
+func NewKafka(opts KafkaOptions, lTypes ...LogType) (*KafkaLogger, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, errors.New("[NewKafka] no brokers")
+	}
+	if opts.Topic == "" {
+		opts.Topic = "lazyevent"
+	}
+	if opts.Format == nil {
+		opts.Format = FormatJSON
+	}
+	if opts.Acks < KafkaAckAll || opts.Acks > KafkaAckNone {
+		return nil, fmt.Errorf("[NewKafka] unsupported acks %d", opts.Acks)
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = "lazyevent"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.TopicFunc == nil {
+		if err := kafkaValidTopic(EventRoute(opts.Topic, Event{}, kafkaTopicChars)); err != nil {
+			return nil, fmt.Errorf("[NewKafka] %w", err)
+		}
+	}
+
+	kopts := []kgo.Opt{
+		kgo.SeedBrokers(opts.Brokers...),
+		kgo.ClientID(opts.ClientID),
+		kgo.RequiredAcks(opts.Acks.kgo()),
+		//nil hasher is murmur2, keyless records go to partitions that have a leader
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+		kgo.DialTimeout(opts.Timeout),
+		kgo.ProduceRequestTimeout(opts.Timeout),
+		kgo.RecordDeliveryTimeout(opts.Timeout),
+		//Leadership changes are retried after metadata refresh: let it happen several times within Timeout
+		kgo.MetadataMinAge(min(opts.Timeout/4, 5*time.Second)),
+	}
+	if opts.AutoCreateTopics {
+		kopts = append(kopts, kgo.AllowAutoTopicCreation())
+	}
+	if opts.Acks != KafkaAckAll {
+		//Idempotent writes require acks from all in-sync replicas
+		kopts = append(kopts, kgo.DisableIdempotentWrite())
+	}
+	if opts.TLSConfig != nil {
+		kopts = append(kopts, kgo.DialTLSConfig(opts.TLSConfig.Clone()))
+	}
+
+	client, err := kgo.NewClient(kopts...)
+	if err != nil {
+		return nil, fmt.Errorf("[NewKafka] %w", err)
+	}
+
+	return &KafkaLogger{lTypes: lTypes, opts: opts, client: client}, nil
+}
+
+// kafkaTopicRune returns true for characters allowed in topic names
+func kafkaTopicRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-'
+}
+
+// kafkaTopicChars replaces characters not allowed in topic names
+var kafkaTopicChars = routeChars(kafkaTopicRune)
+
+// kafkaValidTopic returns ErrInvalidTopic in case topic is not a legal Kafka topic name
+func kafkaValidTopic(s string) error {
+	if s == "" || s == "." || s == ".." || len(s) > kafkaMaxTopic {
+		return fmt.Errorf("%w %q", ErrInvalidTopic, s)
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return !kafkaTopicRune(r) }) >= 0 {
+		return fmt.Errorf("%w %q", ErrInvalidTopic, s)
+	}
+
+	return nil
+}
+
+// Topic returns topic of e
+func (l *KafkaLogger) Topic(e Event) string {
+	if l.opts.TopicFunc != nil {
+		return l.opts.TopicFunc(e)
+	}
+
+	return EventRoute(l.opts.Topic, e, kafkaTopicChars)
+}
+
+// Key returns partition key of e
+func (l *KafkaLogger) Key(e Event) []byte {
+	if l.opts.KeyFunc != nil {
+		return l.opts.KeyFunc(e)
+	}
+	if l.opts.Key == "" {
+		return nil
+	}
+
+	return []byte(EventRoute(l.opts.Key, e, nil))
+}
+
+// Log produces single event
+func (l *KafkaLogger) Log(e Event, timeFormat string) error {
+	if err := l.produce([]Event{e}, timeFormat); err != nil {
+		return fmt.Errorf("[KafkaLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch produces events at once and waits for all of them
+func (l *KafkaLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.produce(events, timeFormat); err != nil {
+		return fmt.Errorf("[KafkaLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+func (l *KafkaLogger) produce(events []Event, timeFormat string) error {
+	var failed []PublishError
+	records := make([]*kgo.Record, 0, len(events))
+	sent := make(map[*kgo.Record]Event, len(events))
+	for _, e := range events {
+		topic := l.Topic(e)
+		if err := kafkaValidTopic(topic); err != nil {
+			failed = append(failed, PublishError{Event: e, Err: err})
+			continue
+		}
+		value, err := l.opts.Format(e, timeFormat)
+		if err != nil {
+			failed = append(failed, PublishError{Event: e, Err: fmt.Errorf("error formatting event: %w", err)})
+			continue
+		}
+		r := &kgo.Record{Topic: topic, Key: l.Key(e), Value: value, Timestamp: e.Time}
+		records = append(records, r)
+		sent[r] = e
+	}
+
+	//Results come in order of completion, records tell which event they belong to
+	for _, res := range l.client.ProduceSync(context.Background(), records...) {
+		if res.Err != nil {
+			failed = append(failed, PublishError{Event: sent[res.Record], Err: res.Err})
+		}
+	}
+	if len(failed) > 0 {
+		return &PublishBatchError{Failed: failed, Total: len(events)}
+	}
+
+	return nil
+}
+
+// Close closes connections to brokers. Logger should not be used after Close
+func (l *KafkaLogger) Close() error {
+	l.client.Close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *KafkaLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// newKafkaCluster starts in-process Kafka cluster, topics are not created automatically
+func newKafkaCluster(t *testing.T, opts ...kfake.Opt) *kfake.Cluster {
+	c, err := kfake.NewCluster(opts...)
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+// kafkaConsume reads n records of topic from the start
+func kafkaConsume(t *testing.T, c *kfake.Cluster, topic string, n int) []*kgo.Record {
+	cl, err := kgo.NewClient(kgo.SeedBrokers(c.ListenAddrs()...), kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()))
+	require.NoError(t, err)
+	defer cl.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < n {
+		fetches := cl.PollFetches(ctx)
+		require.NoError(t, ctx.Err(), "got %d of %d records", len(records), n)
+		records = append(records, fetches.Records()...)
+	}
+
+	return records
+}
+
+func TestKafkaRoute(t *testing.T) {
+	l, err := NewKafka(KafkaOptions{Brokers: []string{"localhost:9092"}, Topic: "logs-{source}", Key: "{level}"}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	e := Error("x").Src(Source{Text: "auth db/1"})
+	assert.Equal(t, "logs-auth_db_1", l.Topic(e))
+	assert.Equal(t, []byte("error"), l.Key(e))
+
+	l.opts.Key = ""
+	assert.Nil(t, l.Key(e))
+
+	_, err = NewKafka(KafkaOptions{}, Any)
+	assert.Error(t, err)
+	_, err = NewKafka(KafkaOptions{Brokers: []string{"localhost:9092"}, Acks: 5}, Any)
+	assert.Error(t, err)
+	_, err = NewKafka(KafkaOptions{Brokers: []string{"localhost:9092"}, Topic: ".."}, Any)
+	assert.ErrorIs(t, err, ErrInvalidTopic)
+
+	for _, s := range []string{"", ".", "..", "logs app", "logs/app", "логи", strings.Repeat("x", 250)} {
+		assert.ErrorIs(t, kafkaValidTopic(s), ErrInvalidTopic, s)
+	}
+	for _, s := range []string{"a", "logs-auth_db.1", "...", strings.Repeat("x", 249)} {
+		assert.NoError(t, kafkaValidTopic(s), s)
+	}
+}
+
+// Batch should be produced at once, keyed events should get partitions of Java clients
+func TestKafkaProduce(t *testing.T) {
+	c := newKafkaCluster(t, kfake.NumBrokers(2), kfake.SeedTopics(4, "logs-info"))
+	l, err := NewKafka(KafkaOptions{
+		Brokers:  c.ListenAddrs()[1:],
+		Topic:    "logs-{level}",
+		Key:      "{source}",
+		ClientID: "billing",
+		Format:   TextFormat(func(e Event, tf string) string { return e.Text }),
+		Timeout:  5 * time.Second,
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	p := New(false, "", make(chan error), false, l)
+	p.SetBatching(BatchPolicy{MaxEvents: 6}, l)
+	sources := []string{"auth", "db", "api", "auth", "queue", "mail"}
+	for i, src := range sources {
+		p.Log(Info("event" + strconv.Itoa(i)).Src(Source{Text: src}))
+	}
+	p.Flush()
+
+	//Partitions of murmur2 (Java DefaultPartitioner) for 4 partitions
+	partitions := map[string]int32{"auth": 3, "db": 2, "api": 0, "queue": 1, "mail": 3}
+	records := kafkaConsume(t, c, "logs-info", len(sources))
+	require.Equal(t, len(sources), len(records))
+	seen := map[string]bool{}
+	for _, r := range records {
+		assert.Equal(t, partitions[string(r.Key)], r.Partition, string(r.Key))
+		seen[string(r.Value)] = true
+	}
+	assert.Equal(t, len(sources), len(seen))
+}
+
+// Keyless events should not go to partitions without leader
+func TestKafkaLeaderless(t *testing.T) {
+	c := newKafkaCluster(t, kfake.NumBrokers(1), kfake.SeedTopics(4, "logs"))
+	host, port, err := net.SplitHostPort(c.ListenAddrs()[0])
+	require.NoError(t, err)
+	port32, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	//Only partition 2 has a leader
+	c.ControlKey(int16(kmsg.Metadata), func(kreq kmsg.Request) (kmsg.Response, error, bool) {
+		c.KeepControl()
+		req := kreq.(*kmsg.MetadataRequest)
+		resp := req.ResponseKind().(*kmsg.MetadataResponse)
+		b := kmsg.NewMetadataResponseBroker()
+		b.NodeID, b.Host, b.Port = 0, host, int32(port32)
+		resp.Brokers = append(resp.Brokers, b)
+		resp.ControllerID = 0
+		st := kmsg.NewMetadataResponseTopic()
+		st.Topic = kmsg.StringPtr("logs")
+		for i := int32(0); i < 4; i++ {
+			sp := kmsg.NewMetadataResponseTopicPartition()
+			sp.Partition = i
+			sp.Leader = -1
+			sp.ErrorCode = kerr.LeaderNotAvailable.Code
+			if i == 2 {
+				sp.Leader, sp.ErrorCode = 0, 0
+				sp.Replicas, sp.ISR = []int32{0}, []int32{0}
+			}
+			st.Partitions = append(st.Partitions, sp)
+		}
+		resp.Topics = append(resp.Topics, st)
+		return resp, nil, true
+	})
+
+	l, err := NewKafka(KafkaOptions{Brokers: c.ListenAddrs(), Topic: "logs", Timeout: 5 * time.Second}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.LogBatch([]Event{Info("1"), Info("2"), Info("3")}, ""))
+	}
+	for _, r := range kafkaConsume(t, c, "logs", 9) {
+		assert.Equal(t, int32(2), r.Partition)
+	}
+}
+
+// Retriable failures should be retried, other failures should be returned per event
+func TestKafkaErrors(t *testing.T) {
+	c := newKafkaCluster(t, kfake.NumBrokers(2), kfake.SeedTopics(2, "app", "db"))
+	l, err := NewKafka(KafkaOptions{
+		Brokers: c.ListenAddrs(),
+		Topic:   "{source}",
+		Acks:    KafkaAckLeader,
+		Timeout: 2 * time.Second,
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.Log(Info("1").Src(Source{Text: "app"}), ""))
+
+	//Leadership change should be retried by the client
+	failed := false
+	c.ControlKey(int16(kmsg.Produce), func(kreq kmsg.Request) (kmsg.Response, error, bool) {
+		req := kreq.(*kmsg.ProduceRequest)
+		resp := req.ResponseKind().(*kmsg.ProduceResponse)
+		for _, rt := range req.Topics {
+			st := kmsg.NewProduceResponseTopic()
+			st.Topic = rt.Topic
+			for _, rp := range rt.Partitions {
+				sp := kmsg.NewProduceResponseTopicPartition()
+				sp.Partition = rp.Partition
+				sp.ErrorCode = kerr.NotLeaderForPartition.Code
+				st.Partitions = append(st.Partitions, sp)
+			}
+			resp.Topics = append(resp.Topics, st)
+		}
+		failed = true
+		return resp, nil, true
+	})
+	require.NoError(t, l.Log(Info("2").Src(Source{Text: "app"}), ""))
+	assert.True(t, failed)
+	assert.Equal(t, 2, len(kafkaConsume(t, c, "app", 2)))
+
+	events := []Event{
+		Info(strings.Repeat("x", 2<<20)).Src(Source{Text: "app"}),
+		Info("4").Src(Source{Text: "missing"}),
+		Info("5").Src(Source{Text: "db"}),
+		Info("6").Src(Source{Text: strings.Repeat("long", 70)}),
+	}
+	err = l.LogBatch(events, "")
+	var pe *PublishBatchError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 4, pe.Total)
+	require.Equal(t, 3, len(pe.Failed))
+	errs := map[string]error{}
+	for _, f := range pe.Failed {
+		errs[f.Event.Text] = f.Err
+	}
+	assert.ErrorIs(t, errs[events[0].Text], kerr.MessageTooLarge)
+	assert.ErrorIs(t, errs["4"], kerr.UnknownTopicOrPartition)
+	assert.ErrorIs(t, errs["6"], ErrInvalidTopic)
+	assert.Contains(t, string(kafkaConsume(t, c, "db", 1)[0].Value), `"text":"5"`)
+}
+
+// Topics should be created only in case logger allows it
+func TestKafkaAutoCreateTopics(t *testing.T) {
+	c := newKafkaCluster(t, kfake.AllowAutoTopicCreation())
+	opts := KafkaOptions{Brokers: c.ListenAddrs(), Topic: "logs-{source}", Timeout: 2 * time.Second}
+
+	l, err := NewKafka(opts, Any)
+	require.NoError(t, err)
+	defer l.Close()
+	assert.ErrorIs(t, l.Log(Info("1").Src(Source{Text: "auth"}), ""), kerr.UnknownTopicOrPartition)
+
+	opts.AutoCreateTopics = true
+	l2, err := NewKafka(opts, Any)
+	require.NoError(t, err)
+	defer l2.Close()
+	require.NoError(t, l2.Log(Info("2").Src(Source{Text: "auth"}), ""))
+	assert.Contains(t, string(kafkaConsume(t, c, "logs-auth", 1)[0].Value), `"text":"2"`)
+}
+
+// Without acks broker does not respond, events should still be delivered
+func TestKafkaAckNone(t *testing.T) {
+	c := newKafkaCluster(t, kfake.SeedTopics(1, "lazyevent"))
+	l, err := NewKafka(KafkaOptions{Brokers: c.ListenAddrs(), Acks: KafkaAckNone, Timeout: time.Second}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.LogBatch([]Event{Info("1"), Info("2")}, ""))
+	require.NoError(t, l.Log(Info("3"), ""))
+	assert.Equal(t, 3, len(kafkaConsume(t, c, "lazyevent", 3)))
+}
@@ -0,0 +1,342 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// ErrOutOfOrder is returned by LokiLogger in case Loki rejected entries as out of order or too old
+var ErrOutOfOrder = errors.New("entries rejected as out of order")
+
+// LokiOptions determines where and how LokiLogger pushes events
+type LokiOptions struct {
+	HTTPOptions
+
+	//URL of Loki, e.g. http://loki:3100. Push API path is added in case URL has no path
+	URL string
+
+	//TenantID is sent as X-Scope-OrgID header in multi-tenant Loki
+	TenantID string
+
+	//Protobuf makes logger push snappy-compressed protobuf instead of JSON
+	Protobuf bool
+
+	//Labels are added to every stream (e.g. {"app": "billing", "env": "prod"})
+	Labels map[string]string
+
+	//LabelFields are event fields that become stream labels: "level", "source" and "type" (default is all three).
+	//Event ID is not supported: every event would have its own stream
+	LabelFields []string
+
+	//LabelFunc returns additional labels of event. Keep number of label values low.
+	//Characters not allowed in Prometheus label names are replaced with "_", empty names are dropped
+	LabelFunc func(e Event) map[string]string
+
+	//ClampTimestamps makes logger shift timestamps of entries older than last pushed entry of the same
+	//stream to that entry's time, so Loki does not reject them as out of order
+	ClampTimestamps bool
+}
+
+// LokiLogger pushes events to Grafana Loki push API. Log lines are JSON records (same as JSON file logger).
+// It implements IBatchLogger: use LogProcessor.SetBatching to push events by size or time.
+// Entries of every stream are sorted by time before push.
+type LokiLogger struct {
+	lTypes   []LogType
+	opts     LokiOptions
+	url      string
+	sender   *httpSender
+	rejected atomic.Uint64
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// lokiStream is a set of entries with the same labels
+type lokiStream struct {
+	labels  map[string]string
+	key     string
+	entries []lokiEntry
+}
+
+type lokiEntry struct {
+	ts   time.Time
+	line string
+}
+
+// NewLoki returns LokiLogger that pushes events to opts.URL
+func NewLoki(opts LokiOptions, lTypes ...LogType) (*LokiLogger, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("[NewLoki] invalid URL %s", opts.URL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/loki/api/v1/push"
+	}
+	if opts.LabelFields == nil {
+		opts.LabelFields = []string{"level", "source", "type"}
+	}
+	for _, f := range opts.LabelFields {
+		if f != "level" && f != "source" && f != "type" {
+			return nil, fmt.Errorf("[NewLoki] unsupported label field %s", f)
+		}
+	}
+	for k := range opts.Labels {
+		if lokiLabelName(k) != k {
+			return nil, fmt.Errorf("[NewLoki] invalid label name %q", k)
+		}
+	}
+
+	return &LokiLogger{
+		lTypes: lTypes,
+		opts:   opts,
+		url:    u.String(),
+		sender: newHTTPSender(opts.HTTPOptions),
+		last:   map[string]time.Time{},
+	}, nil
+}
+
+// Log pushes single event
+func (l *LokiLogger) Log(e Event, timeFormat string) error {
+	if err := l.push([]Event{e}, timeFormat); err != nil {
+		return fmt.Errorf("[LokiLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch pushes all events in one request
+func (l *LokiLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.push(events, timeFormat); err != nil {
+		return fmt.Errorf("[LokiLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+// Rejected returns number of entries Loki rejected as out of order
+func (l *LokiLogger) Rejected() uint64 { return l.rejected.Load() }
+
+func (l *LokiLogger) push(events []Event, timeFormat string) error {
+	streams, err := l.streams(events, timeFormat)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	headers := map[string]string{}
+	if l.opts.Protobuf {
+		body = snappy.Encode(nil, lokiProtobuf(streams))
+		headers["Content-Type"] = "application/x-protobuf"
+	} else {
+		body, err = lokiJSON(streams)
+		if err != nil {
+			return err
+		}
+		headers["Content-Type"] = "application/json"
+	}
+	if l.opts.TenantID != "" {
+		headers["X-Scope-OrgID"] = l.opts.TenantID
+	}
+
+	_, err = l.sender.send("POST", l.url, headers, body)
+	var se *HTTPStatusError
+	if errors.As(err, &se) && se.StatusCode == 400 && lokiOutOfOrder(se.Body) {
+		l.rejected.Add(uint64(len(events)))
+		return fmt.Errorf("%w: %s", ErrOutOfOrder, se.Body)
+	}
+	if err != nil {
+		return err
+	}
+
+	//Last pushed time moves only after Loki has accepted entries: otherwise failed push
+	//would make clamping shift next entries to the time Loki has never seen
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range streams {
+		if ts := s.entries[len(s.entries)-1].ts; ts.After(l.last[s.key]) {
+			l.last[s.key] = ts
+		}
+	}
+
+	return nil
+}
+
+// lokiOutOfOrder returns true in case Loki response body tells entries were rejected because of time
+func lokiOutOfOrder(body string) bool {
+	return strings.Contains(body, "out of order") || strings.Contains(body, "too far behind") ||
+		strings.Contains(body, "too old")
+}
+
+// streams groups events into streams by labels, sorting entries of each stream by time
+func (l *LokiLogger) streams(events []Event, timeFormat string) ([]*lokiStream, error) {
+	byKey := map[string]*lokiStream{}
+	var streams []*lokiStream
+	for _, e := range events {
+		line, err := FormatJSON(e, timeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting event to JSON: %w", err)
+		}
+		labels := l.Labels(e)
+		key := lokiLabelString(labels)
+		s, ok := byKey[key]
+		if !ok {
+			s = &lokiStream{labels: labels, key: key}
+			byKey[key] = s
+			streams = append(streams, s)
+		}
+		s.entries = append(s.entries, lokiEntry{ts: e.Time, line: string(line)})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range streams {
+		sort.SliceStable(s.entries, func(i, j int) bool { return s.entries[i].ts.Before(s.entries[j].ts) })
+		last := l.last[s.key]
+		if l.opts.ClampTimestamps {
+			for i := range s.entries {
+				if s.entries[i].ts.Before(last) {
+					s.entries[i].ts = last
+				}
+			}
+		}
+	}
+
+	return streams, nil
+}
+
+// Labels returns stream labels of e
+func (l *LokiLogger) Labels(e Event) map[string]string {
+	labels := make(map[string]string, len(l.opts.Labels)+len(l.opts.LabelFields))
+	for k, v := range l.opts.Labels {
+		labels[k] = v
+	}
+	for _, f := range l.opts.LabelFields {
+		switch f {
+		case "level":
+			labels["level"] = e.Level.String()
+		case "source":
+			labels["source"] = e.Source.Text
+		case "type":
+			labels["type"] = strconv.Itoa(int(e.Type))
+		}
+	}
+	if l.opts.LabelFunc != nil {
+		for k, v := range l.opts.LabelFunc(e) {
+			if k = lokiLabelName(k); k != "" {
+				labels[k] = v
+			}
+		}
+	}
+	//Loki does not store empty labels
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+
+	return labels
+}
+
+// lokiLabelName turns k into valid Prometheus label name ([a-zA-Z_][a-zA-Z0-9_]*) by replacing
+// other characters with "_". Name starting with a digit gets "_" prefix
+func lokiLabelName(k string) string {
+	if k == "" {
+		return ""
+	}
+	b := []byte(k)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	if b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+
+	return string(b)
+}
+
+// lokiLabelString returns labels in Prometheus format: {a="1", b="2"}
+func lokiLabelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + strconv.Quote(labels[k])
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+type lokiJSONStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func lokiJSON(streams []*lokiStream) ([]byte, error) {
+	req := struct {
+		Streams []lokiJSONStream `json:"streams"`
+	}{}
+	for _, s := range streams {
+		js := lokiJSONStream{Stream: s.labels}
+		for _, e := range s.entries {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(e.ts.UnixNano(), 10), e.line})
+		}
+		req.Streams = append(req.Streams, js)
+	}
+
+	return json.Marshal(req)
+}
+
+// lokiProtobuf encodes logproto.PushRequest:
+//
+//	PushRequest { repeated Stream streams = 1; }
+//	Stream { string labels = 1; repeated Entry entries = 2; }
+//	Entry { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func lokiProtobuf(streams []*lokiStream) []byte {
+	var p protoBuf
+	for _, s := range streams {
+		p.message(1, func(m *protoBuf) {
+			m.string(1, s.key)
+			for _, e := range s.entries {
+				m.message(2, func(em *protoBuf) {
+					em.message(1, func(ts *protoBuf) {
+						ts.int64(1, e.ts.Unix())
+						ts.int64(2, int64(e.ts.Nanosecond()))
+					})
+					em.string(2, e.line)
+				})
+			}
+		})
+	}
+
+	return p.b
+}
+
+// Close interrupts requests & retry delays in progress, they fail with ErrSenderClosed.
+// Logger should not be used after Close
+func (l *LokiLogger) Close() error {
+	l.sender.close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *LokiLogger) Type() []LogType { return l.lTypes }
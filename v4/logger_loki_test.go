@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lokiPush is a decoded push request: stream labels -> entries
+type lokiPush map[string][]lokiEntry
+
+// lokiStub is a local stand-in of Loki push API that decodes JSON & protobuf requests
+type lokiStub struct {
+	t        *testing.T
+	mu       sync.Mutex
+	pushes   []lokiPush
+	headers  []http.Header
+	rejectOO bool
+}
+
+func (s *lokiStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(s.t, "/loki/api/v1/push", r.URL.Path)
+	b, err := io.ReadAll(r.Body)
+	require.NoError(s.t, err)
+
+	push := lokiPush{}
+	if r.Header.Get("Content-Type") == "application/x-protobuf" {
+		b, err = snappy.Decode(nil, b)
+		require.NoError(s.t, err)
+		for _, sf := range protoGet(decodeProto(s.t, b), 1) {
+			stream := decodeProto(s.t, sf.data)
+			labels := string(protoGet(stream, 1)[0].data)
+			for _, ef := range protoGet(stream, 2) {
+				entry := decodeProto(s.t, ef.data)
+				ts := decodeProto(s.t, protoGet(entry, 1)[0].data)
+				var sec, nsec int64
+				if f := protoGet(ts, 1); len(f) > 0 {
+					sec = int64(f[0].val)
+				}
+				if f := protoGet(ts, 2); len(f) > 0 {
+					nsec = int64(f[0].val)
+				}
+				push[labels] = append(push[labels], lokiEntry{ts: time.Unix(sec, nsec), line: string(protoGet(entry, 2)[0].data)})
+			}
+		}
+	} else {
+		var req struct {
+			Streams []lokiJSONStream `json:"streams"`
+		}
+		require.NoError(s.t, json.Unmarshal(b, &req))
+		for _, st := range req.Streams {
+			labels := lokiLabelString(st.Stream)
+			for _, v := range st.Values {
+				ns, err := strconv.ParseInt(v[0], 10, 64)
+				require.NoError(s.t, err)
+				push[labels] = append(push[labels], lokiEntry{ts: time.Unix(0, ns), line: v[1]})
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushes = append(s.pushes, push)
+	s.headers = append(s.headers, r.Header.Clone())
+	if s.rejectOO {
+		http.Error(w, "entry with timestamp 2026-10-18 12:00:00 ignored, reason: 'entry out of order' for stream", 400)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newLokiStub(t *testing.T) (*lokiStub, string) {
+	stub := &lokiStub{t: t}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return stub, srv.URL
+}
+
+var lokiTestTime = time.Date(2026, 10, 18, 12, 0, 0, 5, time.UTC)
+
+func lokiEvents() []Event {
+	e1 := Info("event1").Src(EvsMain)
+	e1.Time = lokiTestTime.Add(time.Second)
+	e2 := Error("event2").Src(EvsMain)
+	e2.Time = lokiTestTime
+	e3 := Info("event3").Src(EvsMain)
+	e3.Time = lokiTestTime
+
+	return []Event{e1, e2, e3}
+}
+
+// Events should be grouped into streams by labels & sorted by time in both formats
+func TestLokiPush(t *testing.T) {
+	for _, proto := range []bool{false, true} {
+		stub, url := newLokiStub(t)
+		l, err := NewLoki(LokiOptions{
+			URL:       url,
+			TenantID:  "team1",
+			Protobuf:  proto,
+			Labels:    map[string]string{"app": "billing"},
+			LabelFunc: func(e Event) map[string]string { return map[string]string{"critical": strconv.FormatBool(e.Level.IsError())} },
+		}, Any)
+		require.NoError(t, err)
+
+		events := lokiEvents()
+		require.NoError(t, l.LogBatch(events, time.RFC3339))
+		require.Equal(t, 1, len(stub.pushes))
+		assert.Equal(t, "team1", stub.headers[0].Get("X-Scope-OrgID"))
+
+		line := func(e Event) string {
+			b, err := FormatJSON(e, time.RFC3339)
+			require.NoError(t, err)
+			return string(b)
+		}
+		assert.Equal(t, lokiPush{
+			`{app="billing", critical="false", level="INFO", source="MAIN", type="0"}`: {
+				{ts: lokiTestTime, line: line(events[2])},
+				{ts: lokiTestTime.Add(time.Second), line: line(events[0])},
+			},
+			`{app="billing", critical="true", level="ERROR", source="MAIN", type="0"}`: {
+				{ts: lokiTestTime, line: line(events[1])},
+			},
+		}, normalizeLokiPush(stub.pushes[0]), "protobuf: %v", proto)
+	}
+}
+
+// normalizeLokiPush makes times comparable by assert.Equal
+func normalizeLokiPush(p lokiPush) lokiPush {
+	for k, entries := range p {
+		for i := range entries {
+			entries[i].ts = entries[i].ts.UTC()
+		}
+		p[k] = entries
+	}
+
+	return p
+}
+
+func TestLokiLabelFields(t *testing.T) {
+	l, err := NewLoki(LokiOptions{URL: "http://loki:3100", LabelFields: []string{"level"}}, Any)
+	require.NoError(t, err)
+	assert.Equal(t, "http://loki:3100/loki/api/v1/push", l.url)
+	assert.Equal(t, map[string]string{"level": "WARNING"}, l.Labels(Warning("w").Src(EvsMain)))
+
+	_, err = NewLoki(LokiOptions{URL: "http://loki:3100", LabelFields: []string{"id"}}, Any)
+	assert.Error(t, err)
+	_, err = NewLoki(LokiOptions{URL: "loki"}, Any)
+	assert.Error(t, err)
+}
+
+// Old entries should be shifted to the last pushed time, rejections should be counted
+func TestLokiOutOfOrder(t *testing.T) {
+	stub, url := newLokiStub(t)
+	l, err := NewLoki(LokiOptions{URL: url, ClampTimestamps: true, LabelFields: []string{}}, Any)
+	require.NoError(t, err)
+
+	events := lokiEvents()
+	require.NoError(t, l.Log(events[0], time.RFC3339))
+	require.NoError(t, l.Log(events[1], time.RFC3339))
+	assert.Equal(t, lokiTestTime.Add(time.Second), stub.pushes[1]["{}"][0].ts.UTC())
+
+	stub.rejectOO = true
+	err = l.LogBatch(events, time.RFC3339)
+	assert.True(t, errors.Is(err, ErrOutOfOrder))
+	assert.Equal(t, uint64(3), l.Rejected())
+}
+
+// Failed push should not move last pushed time, so entries are not clamped to the time Loki has never seen
+func TestLokiClampAfterFailure(t *testing.T) {
+	stub, url := newLokiStub(t)
+	l, err := NewLoki(LokiOptions{URL: url, ClampTimestamps: true, LabelFields: []string{}}, Any)
+	require.NoError(t, err)
+
+	events := lokiEvents()
+	require.NoError(t, l.Log(events[1], time.RFC3339))
+	stub.rejectOO = true
+	assert.Error(t, l.Log(events[0], time.RFC3339))
+	stub.rejectOO = false
+	require.NoError(t, l.Log(events[2], time.RFC3339))
+	assert.Equal(t, lokiTestTime, stub.pushes[2]["{}"][0].ts.UTC())
+}
+
+// Label names should be valid Prometheus label names
+func TestLokiLabelNames(t *testing.T) {
+	l, err := NewLoki(LokiOptions{
+		URL:         "http://loki:3100",
+		LabelFields: []string{},
+		LabelFunc: func(e Event) map[string]string {
+			return map[string]string{"k8s.pod name": "p1", "1st": "a", "": "b", "ok_2": "c"}
+		},
+	}, Any)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"k8s_pod_name": "p1", "_1st": "a", "ok_2": "c"}, l.Labels(Info("e")))
+
+	_, err = NewLoki(LokiOptions{URL: "http://loki:3100", Labels: map[string]string{"app-name": "x"}}, Any)
+	assert.Error(t, err)
+}
@@ -0,0 +1,360 @@
+package logger
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var (
+	//ErrNoResponders is returned in case JetStream acknowledgement is awaited, but no stream listens to the subject
+	ErrNoResponders = jetstream.ErrNoStreamResponse
+
+	//ErrAckTimeout is returned in case server did not acknowledge message in time
+	ErrAckTimeout = errors.New("acknowledgement timeout")
+
+	//ErrMaxPayload is returned in case message is larger than server accepts
+	ErrMaxPayload = nats.ErrMaxPayload
+
+	//ErrInvalidSubject is returned for events with subject that can not be published to
+	//(empty, with whitespace, wildcards or empty tokens)
+	ErrInvalidSubject = errors.New("invalid subject")
+)
+
+// NATSOptions determines where and how NATSLogger publishes events
+type NATSOptions struct {
+	//Addr is host:port (or URL) of NATS server. Default is localhost:4222
+	Addr string
+
+	//Subject is the subject template with EventRoute tokens, e.g. "logs.{source}.{level}".
+	//Default is "lazyevent.{level}"
+	Subject string
+
+	//SubjectFunc returns subject of event instead of Subject template. Events with invalid
+	//subjects fail with ErrInvalidSubject
+	SubjectFunc func(e Event) string
+
+	//Format makes message of event. Default is FormatJSON
+	Format func(e Event, timeFormat string) ([]byte, error)
+
+	//JetStream makes logger wait for stream acknowledgement of every message. Without it logger only
+	//makes sure server has processed messages
+	JetStream bool
+
+	//Token or Username & Password authenticate client
+	Token    string
+	Username string
+	Password string
+
+	//Name of the connection shown by server monitoring
+	Name string
+
+	//TLSConfig turns TLS on. TLS is used anyway in case server requires it
+	TLSConfig *tls.Config
+
+	//Timeout limits connecting and waiting for acknowledgements of a batch. Default is 5 seconds
+	Timeout time.Duration
+}
+
+// NATSLogger publishes events to NATS subjects via nats.go client. It implements IBatchLogger:
+// with batching on (see LogProcessor.SetBatching) messages of a batch are published without
+// waiting for each other and acknowledgements are awaited once for the whole batch.
+// Connection is re-established by the client in case it was broken, events published
+// while it is down fail.
+type NATSLogger struct {
+	lTypes []LogType
+	opts   NATSOptions
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	denied *natsDenied
+
+	mu sync.Mutex
+}
+
+// NewNATS returns NATSLogger connected to opts.Addr
+func NewNATS(opts NATSOptions, lTypes ...LogType) (*NATSLogger, error) {
+	if opts.Addr == "" {
+		opts.Addr = "localhost:4222"
+	}
+	if opts.Subject == "" {
+		opts.Subject = "lazyevent.{level}"
+	}
+	if opts.Format == nil {
+		opts.Format = FormatJSON
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.SubjectFunc == nil {
+		if err := natsValidSubject(EventRoute(opts.Subject, Event{}, natsSubjectChars)); err != nil {
+			return nil, fmt.Errorf("[NewNATS] %w", err)
+		}
+	}
+
+	l := &NATSLogger{lTypes: lTypes, opts: opts, denied: newNATSDenied()}
+	addr := opts.Addr
+	if !strings.Contains(addr, "://") {
+		addr = "nats://" + addr
+	}
+	nopts := []nats.Option{
+		nats.Name(opts.Name),
+		nats.Timeout(opts.Timeout),
+		nats.NoEcho(),
+		nats.MaxReconnects(-1),
+		//Events are not kept while reconnecting: they fail, so LogProcessor can report them
+		nats.ReconnectBufSize(-1),
+		nats.ErrorHandler(l.denied.handle),
+	}
+	if opts.Token != "" {
+		nopts = append(nopts, nats.Token(opts.Token))
+	}
+	if opts.Username != "" {
+		nopts = append(nopts, nats.UserInfo(opts.Username, opts.Password))
+	}
+	if opts.TLSConfig != nil {
+		nopts = append(nopts, nats.Secure(opts.TLSConfig.Clone()))
+	}
+
+	nc, err := nats.Connect(addr, nopts...)
+	if err != nil {
+		return nil, fmt.Errorf("[NewNATS] can not connect to NATS: %w", err)
+	}
+	l.nc = nc
+	if opts.JetStream {
+		if l.js, err = jetstream.New(nc); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("[NewNATS] %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// natsSubjectChars are characters allowed in subject tokens: no delimiters, wildcards or spaces
+var natsSubjectChars = routeChars(func(r rune) bool {
+	return r > ' ' && r != '.' && r != '*' && r != '>' && r != 0x7f && !unicode.IsSpace(r)
+})
+
+// natsValidSubject returns ErrInvalidSubject in case subject is empty, has whitespace or control
+// characters (they would break the protocol line), wildcards or empty tokens
+func natsValidSubject(s string) error {
+	if strings.IndexFunc(s, func(r rune) bool { return r <= ' ' || r == 0x7f || unicode.IsSpace(r) }) >= 0 {
+		return fmt.Errorf("%w %q", ErrInvalidSubject, s)
+	}
+	for _, token := range strings.Split(s, ".") {
+		if token == "" || token == "*" || token == ">" {
+			return fmt.Errorf("%w %q", ErrInvalidSubject, s)
+		}
+	}
+
+	return nil
+}
+
+// Subject returns subject of e
+func (l *NATSLogger) Subject(e Event) string {
+	if l.opts.SubjectFunc != nil {
+		return l.opts.SubjectFunc(e)
+	}
+
+	return EventRoute(l.opts.Subject, e, natsSubjectChars)
+}
+
+// Log publishes single event
+func (l *NATSLogger) Log(e Event, timeFormat string) error {
+	if err := l.publish([]Event{e}, timeFormat); err != nil {
+		return fmt.Errorf("[NATSLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch publishes events and waits for their acknowledgements
+func (l *NATSLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.publish(events, timeFormat); err != nil {
+		return fmt.Errorf("[NATSLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+func (l *NATSLogger) publish(events []Event, timeFormat string) error {
+	var failed []PublishError
+	var msgs []*nats.Msg
+	var sent []Event
+	for _, e := range events {
+		subject := l.Subject(e)
+		if err := natsValidSubject(subject); err != nil {
+			failed = append(failed, PublishError{Event: e, Err: err})
+			continue
+		}
+		data, err := l.opts.Format(e, timeFormat)
+		if err != nil {
+			failed = append(failed, PublishError{Event: e, Err: fmt.Errorf("error formatting event: %w", err)})
+			continue
+		}
+		msgs = append(msgs, &nats.Msg{Subject: subject, Data: data})
+		sent = append(sent, e)
+	}
+
+	//Batches are published one by one, so server errors can be matched with the batch
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.denied.reset()
+	prev := l.nc.LastError()
+	errs := make([]error, len(msgs))
+	acks := make([]jetstream.PubAckFuture, len(msgs))
+	for i, m := range msgs {
+		if l.js != nil {
+			acks[i], errs[i] = l.js.PublishMsgAsync(m, jetstream.WithRetryAttempts(0))
+		} else {
+			errs[i] = l.nc.PublishMsg(m)
+		}
+	}
+
+	//Server handles messages in order: after flush all its errors of the batch have been received.
+	//Permission violations do not tell which message failed, but they name the subject
+	flushErr := l.nc.FlushTimeout(l.opts.Timeout)
+	var denied map[string]error
+	if last := l.nc.LastError(); last != prev {
+		denied = l.denied.wait(last, l.opts.Timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opts.Timeout)
+	defer cancel()
+	for i, m := range msgs {
+		switch {
+		case errs[i] != nil:
+		case denied[m.Subject] != nil:
+			errs[i] = denied[m.Subject]
+		case flushErr != nil:
+			errs[i] = flushErr
+		case acks[i] != nil:
+			errs[i] = natsAck(ctx, acks[i])
+		}
+		if errs[i] != nil {
+			failed = append(failed, PublishError{Event: sent[i], Err: errs[i]})
+		}
+	}
+	if len(failed) > 0 {
+		return &PublishBatchError{Failed: failed, Total: len(events)}
+	}
+
+	return nil
+}
+
+// natsAck waits for JetStream acknowledgement of the message until ctx is done
+func natsAck(ctx context.Context, f jetstream.PubAckFuture) error {
+	select {
+	case <-f.Ok():
+		return nil
+	case err := <-f.Err():
+		return err
+	case <-ctx.Done():
+		return ErrAckTimeout
+	}
+}
+
+// Close closes connection. Logger should not be used after Close
+func (l *NATSLogger) Close() error {
+	l.nc.Close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *NATSLogger) Type() []LogType { return l.lTypes }
+
+// natsDenied collects subjects of permission violations, which client reports asynchronously
+type natsDenied struct {
+	mu       sync.Mutex
+	subjects map[string]error
+	last     error
+	changed  chan struct{}
+}
+
+func newNATSDenied() *natsDenied {
+	return &natsDenied{subjects: map[string]error{}, changed: make(chan struct{})}
+}
+
+// handle is the error handler of connection
+func (d *natsDenied) handle(_ *nats.Conn, _ *nats.Subscription, err error) {
+	subject, ok := natsDeniedSubject(err)
+	if !ok {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subjects[subject] = err
+	d.last = err
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// reset forgets subjects denied before
+func (d *natsDenied) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subjects = map[string]error{}
+}
+
+// wait returns subjects denied since reset. Handler calls are queued in order of errors, so once
+// handler has got last (connection's last error), it has got all previous ones too
+func (d *natsDenied) wait(last error, timeout time.Duration) map[string]error {
+	subject, ok := natsDeniedSubject(last)
+	if !ok {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		d.mu.Lock()
+		changed := d.changed
+		if d.last == last {
+			defer d.mu.Unlock()
+			return maps.Clone(d.subjects)
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			//At least the last denied subject is known
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			subjects := maps.Clone(d.subjects)
+			subjects[subject] = last
+			return subjects
+		}
+	}
+}
+
+// natsDeniedSubject returns subject of publish permissions violation error
+func natsDeniedSubject(err error) (string, bool) {
+	const prefix = "permissions violation for publish to "
+	if err == nil {
+		return "", false
+	}
+	msg := err.Error()
+	i := strings.Index(strings.ToLower(msg), prefix)
+	if i < 0 {
+		return "", false
+	}
+
+	return strings.Trim(msg[i+len(prefix):], `"' `), true
+}
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNATSServer starts in-process NATS server with JetStream. User "user" is not allowed
+// to publish to "app.critical", messages are limited to 1 KB
+func newNATSServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:       "127.0.0.1",
+		Port:       -1,
+		MaxPayload: 1024,
+		JetStream:  true,
+		StoreDir:   t.TempDir(),
+		NoSigs:     true,
+		Users: []*server.User{{
+			Username:    "user",
+			Password:    "pass",
+			Permissions: &server.Permissions{Publish: &server.SubjectPermission{Deny: []string{"app.critical"}}},
+		}},
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+// natsRecorder subscribes to subjects and records messages in order
+type natsRecorder struct {
+	mu   sync.Mutex
+	msgs []*nats.Msg
+}
+
+func newNATSRecorder(t *testing.T, srv *server.Server, subject string) *natsRecorder {
+	nc, err := nats.Connect(srv.ClientURL(), nats.UserInfo("user", "pass"))
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+
+	r := &natsRecorder{}
+	_, err = nc.Subscribe(subject, func(m *nats.Msg) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.msgs = append(r.msgs, m)
+	})
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	return r
+}
+
+func (r *natsRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]string, len(r.msgs))
+	for i, m := range r.msgs {
+		res[i] = m.Subject + " " + string(m.Data)
+	}
+
+	return res
+}
+
+func TestNATSSubject(t *testing.T) {
+	l := &NATSLogger{opts: NATSOptions{Subject: "logs.{source}.{level}.{type}"}}
+	e := Error("x").Src(Source{Text: "auth.db * "})
+	e.Type = ErrorFlow
+	assert.Equal(t, "logs.auth_db___.error.2", l.Subject(e))
+	assert.Equal(t, "logs.none.info.0", l.Subject(Info("x")))
+
+	l.opts.SubjectFunc = func(e Event) string { return "custom" }
+	assert.Equal(t, "custom", l.Subject(e))
+
+	for _, s := range []string{"", "a b", "a\r\nPUB x", "a..b", ".a", "a.", "a.*", "a.>", "a\tb"} {
+		assert.ErrorIs(t, natsValidSubject(s), ErrInvalidSubject, s)
+	}
+	for _, s := range []string{"a", "a.b", "logs.auth_db.error", "a>b", "a*"} {
+		assert.NoError(t, natsValidSubject(s), s)
+	}
+}
+
+// Batch should be published at once with subjects derived from events, server errors should be
+// returned for events they belong to
+func TestNATSPublish(t *testing.T) {
+	srv := newNATSServer(t)
+	rec := newNATSRecorder(t, srv, "app.>")
+	l, err := NewNATS(NATSOptions{
+		Addr:     srv.Addr().String(),
+		Subject:  "app.{level}",
+		Username: "user",
+		Password: "pass",
+		Name:     "billing",
+		Format:   TextFormat(func(e Event, tf string) string { return e.Text }),
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	p := New(false, "", make(chan error), false, l)
+	p.SetBatching(BatchPolicy{MaxEvents: 3}, l)
+	p.Log(Info("event1"))
+	p.Log(Warning("event2"))
+	p.Log(Error("event3"))
+	p.Flush()
+	assert.Eventually(t, func() bool { return len(rec.received()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"app.info event1", "app.warning event2", "app.error event3"}, rec.received())
+	connz, err := srv.Connz(&server.ConnzOptions{})
+	require.NoError(t, err)
+	names := []string{}
+	for _, c := range connz.Conns {
+		names = append(names, c.Name)
+	}
+	assert.Contains(t, names, "billing")
+
+	//Permission violation should fail only events published to the denied subject
+	err = l.LogBatch([]Event{Info("event4"), Critical("event5"), Error("event6")}, "")
+	var pe *PublishBatchError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, 1, len(pe.Failed))
+	assert.Equal(t, "event5", pe.Failed[0].Event.Text)
+	assert.Contains(t, pe.Failed[0].Err.Error(), "Permissions Violation")
+	require.NoError(t, l.Log(Info("event7"), ""))
+
+	//Invalid subjects and larger messages than server accepts should fail without breaking connection
+	l.opts.SubjectFunc = func(e Event) string { return "app." + e.Source.Text }
+	err = l.LogBatch([]Event{
+		Info(strings.Repeat("x", 2000)).Src(Source{Text: "big"}),
+		Info("event8").Src(Source{Text: "x 9\r\nPUB app.injected 1\r\ny"}),
+		Info("event9").Src(Source{Text: "ok"}),
+	}, "")
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, 2, len(pe.Failed))
+	assert.ErrorIs(t, pe.Failed[0].Err, ErrInvalidSubject)
+	assert.ErrorIs(t, pe.Failed[1].Err, ErrMaxPayload)
+	assert.Eventually(t, func() bool { return len(rec.received()) == 7 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "app.ok event9", rec.received()[6])
+
+	//Broken connection should be re-established
+	for _, c := range connz.Conns {
+		if c.Name == "billing" {
+			require.NoError(t, srv.DisconnectClientByID(c.Cid))
+		}
+	}
+	assert.Eventually(t, func() bool { return l.Log(Info("event10").Src(Source{Text: "ok"}), "") == nil }, 5*time.Second, 10*time.Millisecond)
+
+	_, err = NewNATS(NATSOptions{Addr: srv.Addr().String()}, Any)
+	assert.Error(t, err)
+	_, err = NewNATS(NATSOptions{Addr: srv.Addr().String(), Subject: "logs..{level}"}, Any)
+	assert.ErrorIs(t, err, ErrInvalidSubject)
+}
+
+// Every message should be acknowledged by stream, failures should be returned per event
+func TestNATSJetStream(t *testing.T) {
+	srv := newNATSServer(t)
+	nc, err := nats.Connect(srv.ClientURL(), nats.UserInfo("user", "pass"))
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "LOGS", Subjects: []string{"logs.>"}})
+	require.NoError(t, err)
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "FULL", Subjects: []string{"full.>"}, MaxMsgs: 1, Discard: jetstream.DiscardNew})
+	require.NoError(t, err)
+	_, err = js.Publish(ctx, "full.db", []byte("first"))
+	require.NoError(t, err)
+
+	l, err := NewNATS(NATSOptions{
+		Addr:      srv.Addr().String(),
+		Username:  "user",
+		Password:  "pass",
+		JetStream: true,
+		Timeout:   time.Second,
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	//Default subject is not bound to any stream
+	events := []Event{Info("1").Src(EvsMain), Info("2").Src(EvsMain), Info("3").Src(Source{Text: "db"}), Info("4")}
+	err = l.Log(events[0], "")
+	assert.ErrorIs(t, err, ErrNoResponders)
+
+	//Events without source go to subject not bound to any stream, stream of db is full
+	l.opts.SubjectFunc = func(e Event) string {
+		switch e.Source.Text {
+		case "":
+			return "other"
+		case "db":
+			return "full.db"
+		}
+		return EventRoute("logs.{source}", e, natsSubjectChars)
+	}
+	err = l.LogBatch(events, "")
+	var pe *PublishBatchError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 4, pe.Total)
+	require.Equal(t, 2, len(pe.Failed))
+	assert.Equal(t, "3", pe.Failed[0].Event.Text)
+	assert.Contains(t, pe.Failed[0].Err.Error(), "maximum messages exceeded")
+	assert.Equal(t, "4", pe.Failed[1].Event.Text)
+	assert.ErrorIs(t, pe.Failed[1].Err, ErrNoResponders)
+
+	require.NoError(t, l.LogBatch(events[:2], ""))
+	stream, err := js.Stream(ctx, "LOGS")
+	require.NoError(t, err)
+	info, err := stream.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), info.State.Msgs)
+}
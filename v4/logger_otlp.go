@@ -0,0 +1,469 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// OTLPScopeName is the instrumentation scope of records exported by OTLPLogger
+const OTLPScopeName = "github.com/lazybark/lazyevent"
+
+// OTLPOptions determines where and how OTLPLogger exports events
+type OTLPOptions struct {
+	HTTPOptions
+
+	//URL of OTLP/HTTP receiver, e.g. http://collector:4318. Logs path /v1/logs is added in case URL has no path
+	URL string
+
+	//JSON makes logger export OTLP/JSON instead of protobuf
+	JSON bool
+
+	//ServiceName is the service.name resource attribute. Default is unknown_service:<executable name>
+	ServiceName string
+
+	//ResourceAttributes describe the process (e.g. {"deployment.environment": "prod"}).
+	//Values can be strings, bools, integers, floats; other values are sent as strings
+	ResourceAttributes map[string]any
+
+	//Attributes are added to every record
+	Attributes map[string]any
+
+	//AttributesFunc returns additional attributes of event
+	AttributesFunc func(e Event) map[string]any
+
+	//SpanContext returns trace ID, span ID & trace flags from Context of event (see Event.WithContext).
+	//With OpenTelemetry SDK it is:
+	//
+	//	sc := trace.SpanContextFromContext(ctx)
+	//	return sc.TraceID(), sc.SpanID(), byte(sc.TraceFlags())
+	SpanContext func(ctx context.Context) (traceID [16]byte, spanID [8]byte, flags byte)
+}
+
+// OTLPRejectedError is returned in case receiver accepted request, but rejected some records
+type OTLPRejectedError struct {
+	Rejected int64
+	Message  string
+}
+
+func (e *OTLPRejectedError) Error() string {
+	return fmt.Sprintf("%d log records were rejected: %s", e.Rejected, e.Message)
+}
+
+// OTLPLogger exports events as OpenTelemetry log records via OTLP/HTTP, so they can be correlated
+// with traces. It implements IBatchLogger: use LogProcessor.SetBatching to export events in batches.
+// Failed requests are retried as HTTPOptions say.
+type OTLPLogger struct {
+	lTypes   []LogType
+	opts     OTLPOptions
+	url      string
+	resource []otlpKeyValue
+	sender   *httpSender
+}
+
+// otlpKeyValue is an attribute. value is string, bool, int64 or float64
+type otlpKeyValue struct {
+	key   string
+	value any
+}
+
+// otlpRecord is the part of LogRecord taken from event
+type otlpRecord struct {
+	time     time.Time
+	severity int
+	text     string
+	body     string
+	attrs    []otlpKeyValue
+	traceID  []byte
+	spanID   []byte
+	flags    uint32
+}
+
+// NewOTLP returns OTLPLogger that exports events to opts.URL
+func NewOTLP(opts OTLPOptions, lTypes ...LogType) (*OTLPLogger, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("[NewOTLP] invalid URL %s", opts.URL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/logs"
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+
+	resource := map[string]any{"host.name": hostName()}
+	for k, v := range opts.ResourceAttributes {
+		resource[k] = v
+	}
+	resource["service.name"] = opts.ServiceName
+
+	return &OTLPLogger{
+		lTypes:   lTypes,
+		opts:     opts,
+		url:      u.String(),
+		resource: otlpAttributes(resource),
+		sender:   newHTTPSender(opts.HTTPOptions),
+	}, nil
+}
+
+// OTLPSeverity returns OpenTelemetry severity number of level
+func OTLPSeverity(l Level) int {
+	switch l {
+	case FATAL:
+		return 24 //FATAL4
+	case PANIC:
+		return 21 //FATAL
+	case CRIT:
+		return 20 //ERROR4
+	case ERR:
+		return 17 //ERROR
+	case WARN:
+		return 13 //WARN
+	case NOTE:
+		return 10 //INFO2
+	default:
+		return 9 //INFO
+	}
+}
+
+// Log exports single event. timeFormat is unused: records have unix time
+func (l *OTLPLogger) Log(e Event, timeFormat string) error {
+	if err := l.export([]Event{e}); err != nil {
+		return fmt.Errorf("[OTLPLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch exports events with one request
+func (l *OTLPLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.export(events); err != nil {
+		return fmt.Errorf("[OTLPLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+func (l *OTLPLogger) export(events []Event) error {
+	records := make([]otlpRecord, len(events))
+	for i, e := range events {
+		records[i] = l.record(e)
+	}
+	observed := time.Now()
+
+	var body []byte
+	var err error
+	headers := map[string]string{}
+	if l.opts.JSON {
+		body, err = otlpJSON(l.resource, records, observed)
+		if err != nil {
+			return fmt.Errorf("error encoding records: %w", err)
+		}
+		headers["Content-Type"] = "application/json"
+	} else {
+		body = otlpProtobuf(l.resource, records, observed)
+		headers["Content-Type"] = "application/x-protobuf"
+	}
+
+	resp, err := l.sender.send("POST", l.url, headers, body)
+	if err != nil {
+		return err
+	}
+
+	return otlpPartialSuccess(resp, l.opts.JSON)
+}
+
+// record converts e into log record
+func (l *OTLPLogger) record(e Event) otlpRecord {
+	attrs := map[string]any{}
+	for k, v := range l.opts.Attributes {
+		attrs[k] = v
+	}
+	if l.opts.AttributesFunc != nil {
+		for k, v := range l.opts.AttributesFunc(e) {
+			attrs[k] = v
+		}
+	}
+	if e.ID != "" {
+		attrs["log.record.uid"] = e.ID
+	}
+	if e.Source.Text != "" {
+		attrs["lazyevent.source"] = e.Source.Text
+	}
+	attrs["lazyevent.log_type"] = int(e.Type)
+
+	r := otlpRecord{
+		time:     e.Time,
+		severity: OTLPSeverity(e.Level),
+		text:     e.Level.String(),
+		body:     e.Text,
+		attrs:    otlpAttributes(attrs),
+	}
+	if e.Context != nil && l.opts.SpanContext != nil {
+		traceID, spanID, flags := l.opts.SpanContext(e.Context)
+		//All-zero IDs are invalid and mean there is no span
+		if traceID != [16]byte{} {
+			r.traceID = traceID[:]
+		}
+		if spanID != [8]byte{} {
+			r.spanID = spanID[:]
+		}
+		r.flags = uint32(flags)
+	}
+
+	return r
+}
+
+// otlpAttributes returns attributes sorted by key with values converted to OTLP types
+func otlpAttributes(m map[string]any) []otlpKeyValue {
+	attrs := make([]otlpKeyValue, 0, len(m))
+	for k, v := range m {
+		switch val := v.(type) {
+		case string, bool, int64, float64:
+		case int:
+			v = int64(val)
+		case int8:
+			v = int64(val)
+		case int16:
+			v = int64(val)
+		case int32:
+			v = int64(val)
+		case uint8:
+			v = int64(val)
+		case uint16:
+			v = int64(val)
+		case uint32:
+			v = int64(val)
+		case float32:
+			v = float64(val)
+		default:
+			v = fmt.Sprint(val)
+		}
+		attrs = append(attrs, otlpKeyValue{key: k, value: v})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].key < attrs[j].key })
+
+	return attrs
+}
+
+// otlpTime returns time as unix nanoseconds, zero time is 0 (unknown)
+func otlpTime(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return uint64(t.UnixNano())
+}
+
+// otlpProtobuf encodes ExportLogsServiceRequest with one ResourceLogs and one ScopeLogs:
+//
+//	ExportLogsServiceRequest { repeated ResourceLogs resource_logs = 1; }
+//	ResourceLogs { Resource resource = 1; repeated ScopeLogs scope_logs = 2; }
+//	Resource { repeated KeyValue attributes = 1; }
+//	ScopeLogs { InstrumentationScope scope = 1; repeated LogRecord log_records = 2; }
+//	InstrumentationScope { string name = 1; }
+//	LogRecord { fixed64 time_unix_nano = 1; SeverityNumber severity_number = 2; string severity_text = 3;
+//		AnyValue body = 5; repeated KeyValue attributes = 6; fixed32 flags = 8; bytes trace_id = 9;
+//		bytes span_id = 10; fixed64 observed_time_unix_nano = 11; }
+func otlpProtobuf(resource []otlpKeyValue, records []otlpRecord, observed time.Time) []byte {
+	var p protoBuf
+	p.message(1, func(rl *protoBuf) {
+		rl.message(1, func(res *protoBuf) {
+			otlpProtoAttributes(res, 1, resource)
+		})
+		rl.message(2, func(sl *protoBuf) {
+			sl.message(1, func(scope *protoBuf) {
+				scope.string(1, OTLPScopeName)
+			})
+			for _, r := range records {
+				sl.message(2, func(lr *protoBuf) {
+					lr.fixed64(1, otlpTime(r.time))
+					lr.uvarint(2, uint64(r.severity))
+					lr.string(3, r.text)
+					lr.message(5, func(v *protoBuf) {
+						otlpProtoValue(v, r.body)
+					})
+					otlpProtoAttributes(lr, 6, r.attrs)
+					lr.fixed32(8, r.flags)
+					lr.bytes(9, r.traceID)
+					lr.bytes(10, r.spanID)
+					lr.fixed64(11, otlpTime(observed))
+				})
+			}
+		})
+	})
+
+	return p.b
+}
+
+// otlpProtoAttributes writes KeyValue { string key = 1; AnyValue value = 2; } for every attribute
+func otlpProtoAttributes(p *protoBuf, field int, attrs []otlpKeyValue) {
+	for _, a := range attrs {
+		p.message(field, func(kv *protoBuf) {
+			kv.string(1, a.key)
+			kv.message(2, func(v *protoBuf) {
+				otlpProtoValue(v, a.value)
+			})
+		})
+	}
+}
+
+// otlpProtoValue writes AnyValue { oneof { string string_value = 1; bool bool_value = 2;
+// int64 int_value = 3; double double_value = 4; } }. Zero values are written explicitly,
+// because oneof field presence matters
+func otlpProtoValue(p *protoBuf, value any) {
+	switch v := value.(type) {
+	case string:
+		p.tag(1, protoBytes)
+		p.varint(uint64(len(v)))
+		p.b = append(p.b, v...)
+	case bool:
+		p.tag(2, protoVarint)
+		if v {
+			p.varint(1)
+		} else {
+			p.varint(0)
+		}
+	case int64:
+		p.tag(3, protoVarint)
+		p.varint(uint64(v))
+	case float64:
+		p.tag(4, protoFixed64)
+		p.b = binary.LittleEndian.AppendUint64(p.b, math.Float64bits(v))
+	}
+}
+
+// otlpJSONValue returns AnyValue in OTLP/JSON encoding: 64-bit integers are strings
+func otlpJSONValue(value any) map[string]any {
+	switch v := value.(type) {
+	case bool:
+		return map[string]any{"boolValue": v}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]any{"doubleValue": v}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(v)}
+	}
+}
+
+func otlpJSONAttributes(attrs []otlpKeyValue) []map[string]any {
+	res := make([]map[string]any, len(attrs))
+	for i, a := range attrs {
+		res[i] = map[string]any{"key": a.key, "value": otlpJSONValue(a.value)}
+	}
+
+	return res
+}
+
+// otlpJSON encodes ExportLogsServiceRequest in OTLP/JSON: field names are lowerCamelCase,
+// trace & span IDs are hex, 64-bit integers are strings
+func otlpJSON(resource []otlpKeyValue, records []otlpRecord, observed time.Time) ([]byte, error) {
+	logRecords := make([]map[string]any, len(records))
+	for i, r := range records {
+		lr := map[string]any{
+			"timeUnixNano":         strconv.FormatUint(otlpTime(r.time), 10),
+			"observedTimeUnixNano": strconv.FormatUint(otlpTime(observed), 10),
+			"severityNumber":       r.severity,
+			"severityText":         r.text,
+			"body":                 otlpJSONValue(r.body),
+			"attributes":           otlpJSONAttributes(r.attrs),
+		}
+		if r.traceID != nil {
+			lr["traceId"] = hex.EncodeToString(r.traceID)
+		}
+		if r.spanID != nil {
+			lr["spanId"] = hex.EncodeToString(r.spanID)
+		}
+		if r.flags != 0 {
+			lr["flags"] = r.flags
+		}
+		logRecords[i] = lr
+	}
+
+	req := map[string]any{
+		"resourceLogs": []any{map[string]any{
+			"resource": map[string]any{"attributes": otlpJSONAttributes(resource)},
+			"scopeLogs": []any{map[string]any{
+				"scope":      map[string]any{"name": OTLPScopeName},
+				"logRecords": logRecords,
+			}},
+		}},
+	}
+
+	return json.Marshal(req)
+}
+
+// otlpPartialSuccess returns OTLPRejectedError in case response tells some records were rejected:
+//
+//	ExportLogsServiceResponse { ExportLogsPartialSuccess partial_success = 1; }
+//	ExportLogsPartialSuccess { int64 rejected_log_records = 1; string error_message = 2; }
+func otlpPartialSuccess(resp []byte, isJSON bool) error {
+	if len(resp) == 0 {
+		return nil
+	}
+
+	var rejected int64
+	var msg string
+	if isJSON {
+		var r struct {
+			PartialSuccess struct {
+				//int64 may come both as string and number
+				RejectedLogRecords json.Number `json:"rejectedLogRecords"`
+				ErrorMessage       string      `json:"errorMessage"`
+			} `json:"partialSuccess"`
+		}
+		if err := json.Unmarshal(resp, &r); err != nil {
+			//Response body is not required to be meaningful
+			return nil
+		}
+		rejected, _ = r.PartialSuccess.RejectedLogRecords.Int64()
+		msg = r.PartialSuccess.ErrorMessage
+	} else {
+		err := protoScan(resp, func(field int, wire int, val uint64, data []byte) {
+			if field != 1 || wire != protoBytes {
+				return
+			}
+			protoScan(data, func(field int, wire int, val uint64, data []byte) {
+				switch {
+				case field == 1 && wire == protoVarint:
+					rejected = int64(val)
+				case field == 2 && wire == protoBytes:
+					msg = string(data)
+				}
+			})
+		})
+		if err != nil {
+			return nil
+		}
+	}
+	if rejected > 0 {
+		return &OTLPRejectedError{Rejected: rejected, Message: msg}
+	}
+
+	return nil
+}
+
+// Close interrupts requests & retry delays in progress, they fail with ErrSenderClosed.
+// Logger should not be used after Close
+func (l *OTLPLogger) Close() error {
+	l.sender.close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *OTLPLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,349 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otlpTestRecord is a log record decoded from either encoding. Trace IDs are hex, int attributes are int64
+type otlpTestRecord struct {
+	Time     uint64
+	Severity int
+	Text     string
+	Body     string
+	Attrs    map[string]any
+	TraceID  string
+	SpanID   string
+	Flags    uint32
+}
+
+// otlpExport is a decoded export request
+type otlpExport struct {
+	Resource map[string]any
+	Scope    string
+	Records  []otlpTestRecord
+}
+
+// otlpStub is a local stand-in of OTLP/HTTP logs receiver. Requests fail with status in failures
+// (one status per request), response is taken from partial if set
+type otlpStub struct {
+	t        *testing.T
+	mu       sync.Mutex
+	exports  []otlpExport
+	failures []int
+	partial  []byte
+}
+
+func newOTLPStub(t *testing.T) (*otlpStub, string) {
+	stub := &otlpStub{t: t}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return stub, srv.URL
+}
+
+func (s *otlpStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assert.Equal(s.t, "/v1/logs", r.URL.Path)
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		w.WriteHeader(status)
+		return
+	}
+	b, err := io.ReadAll(r.Body)
+	require.NoError(s.t, err)
+	if r.Header.Get("Content-Type") == "application/x-protobuf" {
+		s.exports = append(s.exports, s.decodeProtobuf(b))
+	} else {
+		assert.Equal(s.t, "application/json", r.Header.Get("Content-Type"))
+		s.exports = append(s.exports, s.decodeJSON(b))
+	}
+	w.Write(s.partial)
+}
+
+func (s *otlpStub) protoValue(b []byte) any {
+	f := decodeProto(s.t, b)
+	require.Equal(s.t, 1, len(f))
+	switch f[0].num {
+	case 1:
+		return string(f[0].data)
+	case 2:
+		return f[0].val == 1
+	case 3:
+		return int64(f[0].val)
+	default:
+		return math.Float64frombits(f[0].val)
+	}
+}
+
+func (s *otlpStub) protoAttributes(fields []protoField) map[string]any {
+	attrs := map[string]any{}
+	for _, a := range fields {
+		kv := decodeProto(s.t, a.data)
+		attrs[string(protoGet(kv, 1)[0].data)] = s.protoValue(protoGet(kv, 2)[0].data)
+	}
+
+	return attrs
+}
+
+func (s *otlpStub) decodeProtobuf(b []byte) otlpExport {
+	var exp otlpExport
+	rl := protoGet(decodeProto(s.t, b), 1)
+	require.Equal(s.t, 1, len(rl))
+	rlf := decodeProto(s.t, rl[0].data)
+	exp.Resource = s.protoAttributes(protoGet(decodeProto(s.t, protoGet(rlf, 1)[0].data), 1))
+
+	sl := protoGet(rlf, 2)
+	require.Equal(s.t, 1, len(sl))
+	slf := decodeProto(s.t, sl[0].data)
+	exp.Scope = string(decodeProto(s.t, protoGet(slf, 1)[0].data)[0].data)
+	for _, lr := range protoGet(slf, 2) {
+		f := decodeProto(s.t, lr.data)
+		r := otlpTestRecord{
+			Time:     protoGet(f, 1)[0].val,
+			Severity: int(protoGet(f, 2)[0].val),
+			Text:     string(protoGet(f, 3)[0].data),
+			Body:     s.protoValue(protoGet(f, 5)[0].data).(string),
+			Attrs:    s.protoAttributes(protoGet(f, 6)),
+		}
+		assert.NotZero(s.t, protoGet(f, 11)[0].val)
+		if v := protoGet(f, 8); len(v) > 0 {
+			r.Flags = uint32(v[0].val)
+		}
+		if v := protoGet(f, 9); len(v) > 0 {
+			r.TraceID = hex.EncodeToString(v[0].data)
+		}
+		if v := protoGet(f, 10); len(v) > 0 {
+			r.SpanID = hex.EncodeToString(v[0].data)
+		}
+		exp.Records = append(exp.Records, r)
+	}
+
+	return exp
+}
+
+type otlpJSONKeyValue struct {
+	Key   string                     `json:"key"`
+	Value map[string]json.RawMessage `json:"value"`
+}
+
+func (s *otlpStub) jsonAttributes(kvs []otlpJSONKeyValue) map[string]any {
+	attrs := map[string]any{}
+	for _, kv := range kvs {
+		for typ, raw := range kv.Value {
+			var v any
+			require.NoError(s.t, json.Unmarshal(raw, &v))
+			switch typ {
+			case "intValue":
+				//64-bit integers must be strings
+				n, err := strconv.ParseInt(v.(string), 10, 64)
+				require.NoError(s.t, err)
+				v = n
+			case "doubleValue":
+				v = v.(float64)
+			}
+			attrs[kv.Key] = v
+		}
+	}
+
+	return attrs
+}
+
+func (s *otlpStub) decodeJSON(b []byte) otlpExport {
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []otlpJSONKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				Scope struct {
+					Name string `json:"name"`
+				} `json:"scope"`
+				LogRecords []struct {
+					TimeUnixNano         string             `json:"timeUnixNano"`
+					ObservedTimeUnixNano string             `json:"observedTimeUnixNano"`
+					SeverityNumber       int                `json:"severityNumber"`
+					SeverityText         string             `json:"severityText"`
+					Body                 map[string]string  `json:"body"`
+					Attributes           []otlpJSONKeyValue `json:"attributes"`
+					TraceID              string             `json:"traceId"`
+					SpanID               string             `json:"spanId"`
+					Flags                uint32             `json:"flags"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	require.NoError(s.t, json.Unmarshal(b, &req))
+	require.Equal(s.t, 1, len(req.ResourceLogs))
+	require.Equal(s.t, 1, len(req.ResourceLogs[0].ScopeLogs))
+
+	sl := req.ResourceLogs[0].ScopeLogs[0]
+	exp := otlpExport{Resource: s.jsonAttributes(req.ResourceLogs[0].Resource.Attributes), Scope: sl.Scope.Name}
+	for _, lr := range sl.LogRecords {
+		ts, err := strconv.ParseUint(lr.TimeUnixNano, 10, 64)
+		require.NoError(s.t, err)
+		assert.NotEqual(s.t, "", lr.ObservedTimeUnixNano)
+		exp.Records = append(exp.Records, otlpTestRecord{
+			Time:     ts,
+			Severity: lr.SeverityNumber,
+			Text:     lr.SeverityText,
+			Body:     lr.Body["stringValue"],
+			Attrs:    s.jsonAttributes(lr.Attributes),
+			TraceID:  lr.TraceID,
+			SpanID:   lr.SpanID,
+			Flags:    lr.Flags,
+		})
+	}
+
+	return exp
+}
+
+type otlpSpanKey struct{}
+
+// otlpTestSpan takes trace & span IDs put into context by test
+func otlpTestSpan(ctx context.Context) (traceID [16]byte, spanID [8]byte, flags byte) {
+	ids, ok := ctx.Value(otlpSpanKey{}).([2]uint64)
+	if !ok {
+		return
+	}
+	binary.BigEndian.PutUint64(traceID[8:], ids[0])
+	binary.BigEndian.PutUint64(spanID[:], ids[1])
+
+	return traceID, spanID, 1
+}
+
+// Both encodings should carry the same records: severity, body, attributes & trace context
+func TestOTLPExport(t *testing.T) {
+	ts := time.Date(2026, 10, 19, 12, 0, 0, 5, time.UTC)
+	ctx := context.WithValue(context.Background(), otlpSpanKey{}, [2]uint64{0xabc, 0xdef})
+	e1 := Warning("disk is almost full").Src(EvsMain).SetID("42").WithContext(ctx)
+	e1.Time = ts
+	e2 := Fatal("disk full")
+	e2.Time = ts.Add(time.Second)
+
+	for _, isJSON := range []bool{false, true} {
+		stub, url := newOTLPStub(t)
+		l, err := NewOTLP(OTLPOptions{
+			URL:                url,
+			JSON:               isJSON,
+			ServiceName:        "billing",
+			ResourceAttributes: map[string]any{"deployment.environment": "prod"},
+			Attributes:         map[string]any{"region": "eu", "shard": 3, "ratio": 0.5, "canary": true},
+			AttributesFunc:     func(e Event) map[string]any { return map[string]any{"error": e.Level.IsError()} },
+			SpanContext:        otlpTestSpan,
+		}, Any)
+		require.NoError(t, err)
+		require.NoError(t, l.LogBatch([]Event{e1, e2}, ""))
+		require.Equal(t, 1, len(stub.exports))
+
+		static := func(m map[string]any) map[string]any {
+			m["region"], m["shard"], m["ratio"], m["canary"] = "eu", int64(3), 0.5, true
+			return m
+		}
+		assert.Equal(t, otlpExport{
+			Resource: map[string]any{"service.name": "billing", "host.name": hostName(), "deployment.environment": "prod"},
+			Scope:    OTLPScopeName,
+			Records: []otlpTestRecord{
+				{
+					Time: uint64(ts.UnixNano()), Severity: 13, Text: "WARNING", Body: "disk is almost full",
+					Attrs:   static(map[string]any{"error": false, "log.record.uid": "42", "lazyevent.source": "MAIN", "lazyevent.log_type": int64(0)}),
+					TraceID: "00000000000000000000000000000abc", SpanID: "0000000000000def", Flags: 1,
+				},
+				{
+					Time: uint64(ts.Add(time.Second).UnixNano()), Severity: 24, Text: "FATAL", Body: "disk full",
+					Attrs: static(map[string]any{"error": true, "lazyevent.log_type": int64(0)}),
+				},
+			},
+		}, stub.exports[0], "JSON: %v", isJSON)
+	}
+}
+
+func TestOTLPOptions(t *testing.T) {
+	l, err := NewOTLP(OTLPOptions{URL: "http://collector:4318"}, Any)
+	require.NoError(t, err)
+	assert.Equal(t, "http://collector:4318/v1/logs", l.url)
+	assert.Equal(t, 21, OTLPSeverity(PANIC))
+
+	//Context without span should not produce IDs
+	l.opts.SpanContext = otlpTestSpan
+	r := l.record(Info("x").WithContext(context.Background()))
+	assert.Nil(t, r.traceID)
+	assert.Nil(t, r.spanID)
+
+	_, err = NewOTLP(OTLPOptions{URL: "collector"}, Any)
+	assert.Error(t, err)
+}
+
+// Unavailable receiver should be retried, rejected records should be reported
+func TestOTLPRetryAndPartialSuccess(t *testing.T) {
+	for _, isJSON := range []bool{false, true} {
+		stub, url := newOTLPStub(t)
+		l, err := NewOTLP(OTLPOptions{URL: url, JSON: isJSON, HTTPOptions: HTTPOptions{MaxRetries: 2}}, Any)
+		require.NoError(t, err)
+		var waits []time.Duration
+		l.sender.sleep = func(d time.Duration) bool { waits = append(waits, d); return true }
+
+		stub.failures = []int{503, 429}
+		require.NoError(t, l.Log(Info("x"), ""))
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+		assert.Equal(t, 1, len(stub.exports))
+
+		if isJSON {
+			stub.partial = []byte(`{"partialSuccess":{"rejectedLogRecords":"1","errorMessage":"too old"}}`)
+		} else {
+			var p protoBuf
+			p.message(1, func(m *protoBuf) {
+				m.int64(1, 1)
+				m.string(2, "too old")
+			})
+			stub.partial = p.b
+		}
+		err = l.Log(Info("x"), "")
+		var re *OTLPRejectedError
+		require.True(t, errors.As(err, &re), "JSON: %v", isJSON)
+		assert.Equal(t, &OTLPRejectedError{Rejected: 1, Message: "too old"}, re)
+
+		//Empty partial success means full success
+		stub.partial = []byte(`{"partialSuccess":{}}`)
+		if !isJSON {
+			stub.partial = nil
+		}
+		assert.NoError(t, l.Log(Info("x"), ""))
+	}
+}
+
+// Events batched by LogProcessor should be exported with one request
+func TestOTLPBatching(t *testing.T) {
+	stub, url := newOTLPStub(t)
+	l, err := NewOTLP(OTLPOptions{URL: url}, Any)
+	require.NoError(t, err)
+
+	p := New(false, "", make(chan error), false, l)
+	p.SetBatching(BatchPolicy{MaxEvents: 3, MaxLatency: time.Hour}, l)
+	for i := 0; i < 3; i++ {
+		p.Log(Info(strconv.Itoa(i)))
+	}
+	p.Flush()
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	require.Equal(t, 1, len(stub.exports))
+	assert.Equal(t, 3, len(stub.exports[0].Records))
+}
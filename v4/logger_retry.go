@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by RetryLogger in case circuit is open and events are not sent to the logger
+var ErrCircuitOpen = errors.New("circuit is open")
+
+// CircuitState represents state of RetryLogger circuit breaker
+type CircuitState int
+
+const (
+	//CircuitClosed means logger works fine and receives all events
+	CircuitClosed CircuitState = iota
+
+	//CircuitOpen means logger has failed too many times and does not receive events
+	CircuitOpen
+
+	//CircuitHalfOpen means one probe event is being sent to check if logger has recovered
+	CircuitHalfOpen
+)
+
+var circuitStateNames = [...]string{
+	"CLOSED",
+	"OPEN",
+	"HALF-OPEN",
+}
+
+func (s CircuitState) String() string {
+	if s < CircuitClosed || s > CircuitHalfOpen {
+		return ""
+	}
+
+	return circuitStateNames[s]
+}
+
+// RetryPolicy determines how RetryLogger retries failed events and when it opens the circuit
+type RetryPolicy struct {
+	//MaxRetries is the number of retries after first failed attempt
+	MaxRetries int
+
+	//BaseDelay is the delay before first retry. Each next delay is twice as long
+	BaseDelay time.Duration
+
+	//MaxDelay limits delay between retries. Zero means no limit
+	MaxDelay time.Duration
+
+	//Jitter is the part of delay (0..1) that is randomly subtracted from it
+	//to avoid retries from different routines at the same moment. Values out of 0..1 are rejected by NewRetry
+	Jitter float64
+
+	//FailureThreshold is the number of failed events in a row that opens the circuit.
+	//Zero means circuit is never opened
+	FailureThreshold int
+
+	//OpenTimeout is the time circuit stays open before probe event is sent to the logger
+	OpenTimeout time.Duration
+}
+
+// RetryStatus represents current state of RetryLogger
+type RetryStatus struct {
+	State     CircuitState
+	Failures  int
+	OpenedAt  time.Time
+	LastError error
+}
+
+// RetryLogger wraps any ILogger to retry failed events with exponential backoff & jitter
+// and stop sending events to the logger after several failures (open the circuit).
+// After OpenTimeout has passed, next event is used as a probe: in case of success circuit is closed again.
+type RetryLogger struct {
+	lg     ILogger
+	policy RetryPolicy
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	lastErr  error
+
+	now   func() time.Time
+	sleep func(time.Duration)
+	rnd   func() float64
+}
+
+// NewRetry returns RetryLogger that wraps lg and uses p to retry events and open the circuit
+func NewRetry(lg ILogger, p RetryPolicy) (*RetryLogger, error) {
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return nil, fmt.Errorf("[NewRetry] jitter %v is out of 0..1", p.Jitter)
+	}
+
+	return &RetryLogger{
+		lg:     lg,
+		policy: p,
+		now:    time.Now,
+		sleep:  time.Sleep,
+		rnd:    rand.Float64,
+	}, nil
+}
+
+// Log passes event to the wrapped logger, retrying in case of error.
+// It returns ErrCircuitOpen without calling the logger while circuit is open.
+func (l *RetryLogger) Log(e Event, timeFormat string) error {
+	ok, probe := l.allow()
+	if !ok {
+		return fmt.Errorf("[RetryLogger][Log] %w", ErrCircuitOpen)
+	}
+
+	//Probe is a single attempt: we only need to know if logger has recovered
+	retries := l.policy.MaxRetries
+	if probe {
+		retries = 0
+	}
+
+	//Panic of the logger is a failure too: otherwise probe stays in flight and circuit never closes
+	defer func() {
+		if r := recover(); r != nil {
+			l.done(LoggerPanic{Value: r, Stack: debug.Stack()})
+			panic(r)
+		}
+	}()
+
+	var err error
+	for i := 0; i <= retries; i++ {
+		if err = l.lg.Log(e, timeFormat); err == nil {
+			break
+		}
+		if i < retries {
+			l.sleep(l.Backoff(i))
+		}
+	}
+
+	l.done(err)
+	if err != nil {
+		return fmt.Errorf("[RetryLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// Type returns set of types supported by the wrapped logger
+func (l *RetryLogger) Type() []LogType { return l.lg.Type() }
+
+// State returns current circuit state
+func (l *RetryLogger) State() CircuitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.state
+}
+
+// Status returns current circuit state, number of failed events in a row and last error
+func (l *RetryLogger) Status() RetryStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return RetryStatus{State: l.state, Failures: l.failures, OpenedAt: l.openedAt, LastError: l.lastErr}
+}
+
+// Backoff returns delay before retry number n (starting from 0)
+func (l *RetryLogger) Backoff(n int) time.Duration {
+	limit := l.policy.MaxDelay
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	d := l.policy.BaseDelay
+	for i := 0; i < n && d < limit; i++ {
+		//Doubling would exceed the limit (or overflow in case there is none)
+		if d > limit/2 {
+			d = limit
+			break
+		}
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	if l.policy.Jitter > 0 {
+		d -= time.Duration(l.rnd() * l.policy.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// allow returns true in case event can be sent to the logger and whether the event is a probe.
+// It switches open circuit to half-open after OpenTimeout.
+func (l *RetryLogger) allow() (ok bool, probe bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch l.state {
+	case CircuitOpen:
+		if l.now().Sub(l.openedAt) < l.policy.OpenTimeout {
+			return false, false
+		}
+		l.state = CircuitHalfOpen
+		return true, true
+	case CircuitHalfOpen:
+		//Only one probe at a time
+		return false, false
+	}
+
+	return true, false
+}
+
+// done updates circuit state according to the result of logging
+func (l *RetryLogger) done(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err == nil {
+		l.state = CircuitClosed
+		l.failures = 0
+		l.lastErr = nil
+		return
+	}
+
+	l.failures++
+	l.lastErr = err
+	if l.state == CircuitHalfOpen || (l.policy.FailureThreshold > 0 && l.failures >= l.policy.FailureThreshold) {
+		l.state = CircuitOpen
+		l.openedAt = l.now()
+	}
+}
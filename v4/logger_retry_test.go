@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyLogger fails first fails calls
+type flakyLogger struct {
+	fails int
+	calls int
+}
+
+func (l *flakyLogger) Log(e Event, timeFormat string) error {
+	l.calls++
+	if l.calls <= l.fails {
+		return fmt.Errorf("call %d failed", l.calls)
+	}
+	return nil
+}
+func (l *flakyLogger) Type() []LogType { return []LogType{Main} }
+
+func newTestRetry(lg ILogger, p RetryPolicy) (*RetryLogger, *time.Time, *[]time.Duration) {
+	now := time.Now()
+	var sleeps []time.Duration
+	rl, err := NewRetry(lg, p)
+	if err != nil {
+		panic(err)
+	}
+	rl.now = func() time.Time { return now }
+	rl.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	rl.rnd = func() float64 { return 0.5 }
+
+	return rl, &now, &sleeps
+}
+
+func TestLoggerRetryType(t *testing.T) {
+	rl, err := NewRetry(&flakyLogger{}, RetryPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, []LogType{Main}, rl.Type())
+
+	_, err = NewRetry(&flakyLogger{}, RetryPolicy{Jitter: 1.5})
+	assert.Error(t, err)
+	_, err = NewRetry(&flakyLogger{}, RetryPolicy{Jitter: -0.1})
+	assert.Error(t, err)
+}
+
+func TestLoggerRetryBackoff(t *testing.T) {
+	rl, _, _ := newTestRetry(&flakyLogger{}, RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	assert.Equal(t, time.Second, rl.Backoff(0))
+	assert.Equal(t, 2*time.Second, rl.Backoff(1))
+	assert.Equal(t, 4*time.Second, rl.Backoff(2))
+	assert.Equal(t, 5*time.Second, rl.Backoff(3))
+	assert.Equal(t, 5*time.Second, rl.Backoff(100))
+
+	rl.policy.Jitter = 0.5
+	assert.Equal(t, 750*time.Millisecond, rl.Backoff(0))
+
+	//Without MaxDelay doubling should stop at the largest duration instead of overflowing
+	rl.policy = RetryPolicy{BaseDelay: time.Second}
+	assert.Equal(t, 1<<33*time.Second, rl.Backoff(33))
+	for _, n := range []int{34, 40, 63, 64, 1000} {
+		assert.Equal(t, time.Duration(math.MaxInt64), rl.Backoff(n), n)
+	}
+	rl.policy.Jitter = 1
+	assert.True(t, rl.Backoff(1000) > 0)
+}
+
+// Logger should be called again until success or MaxRetries
+func TestLoggerRetryLog(t *testing.T) {
+	lg := &flakyLogger{fails: 2}
+	rl, _, sleeps := newTestRetry(lg, RetryPolicy{MaxRetries: 3, BaseDelay: time.Second})
+
+	require.NoError(t, rl.Log(Info("event1"), time.UnixDate))
+	assert.Equal(t, 3, lg.calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *sleeps)
+
+	lg2 := &flakyLogger{fails: 10}
+	rl2, _, _ := newTestRetry(lg2, RetryPolicy{MaxRetries: 3})
+	assert.Error(t, rl2.Log(Info("event1"), time.UnixDate))
+	assert.Equal(t, 4, lg2.calls)
+}
+
+// Circuit should open after threshold, then probe and close after success
+type panicLogger struct{ panics bool }
+
+func (l *panicLogger) Log(e Event, timeFormat string) error {
+	if l.panics {
+		panic("logger is broken")
+	}
+	return nil
+}
+func (l *panicLogger) Type() []LogType { return []LogType{Any} }
+
+// Panicking probe should open circuit again instead of leaving it half-open forever
+func TestLoggerRetryProbePanic(t *testing.T) {
+	lg := &panicLogger{panics: true}
+	rl, now, _ := newTestRetry(lg, RetryPolicy{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	assert.Panics(t, func() { rl.Log(Info("event1"), time.UnixDate) })
+	assert.Equal(t, CircuitOpen, rl.State())
+
+	*now = now.Add(2 * time.Minute)
+	assert.PanicsWithValue(t, "logger is broken", func() { rl.Log(Info("event2"), time.UnixDate) })
+	assert.Equal(t, CircuitOpen, rl.State())
+	var lpanic LoggerPanic
+	assert.ErrorAs(t, rl.Status().LastError, &lpanic)
+
+	lg.panics = false
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, rl.Log(Info("event3"), time.UnixDate))
+	assert.Equal(t, CircuitClosed, rl.State())
+}
+
+func TestLoggerRetryCircuit(t *testing.T) {
+	lg := &flakyLogger{fails: 3}
+	rl, now, _ := newTestRetry(lg, RetryPolicy{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	assert.Error(t, rl.Log(Info("event1"), time.UnixDate))
+	assert.Equal(t, CircuitClosed, rl.State())
+	assert.Error(t, rl.Log(Info("event2"), time.UnixDate))
+	assert.Equal(t, CircuitOpen, rl.State())
+
+	//Open circuit should not call the logger
+	assert.ErrorIs(t, rl.Log(Info("event3"), time.UnixDate), ErrCircuitOpen)
+	assert.Equal(t, 2, lg.calls)
+
+	//Failed probe should open circuit again
+	*now = now.Add(2 * time.Minute)
+	assert.Error(t, rl.Log(Info("event4"), time.UnixDate))
+	assert.Equal(t, CircuitOpen, rl.State())
+	assert.Equal(t, 3, rl.Status().Failures)
+	assert.ErrorIs(t, rl.Log(Info("event5"), time.UnixDate), ErrCircuitOpen)
+
+	//Successful probe should close circuit
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, rl.Log(Info("event6"), time.UnixDate))
+	assert.Equal(t, CircuitClosed, rl.State())
+	assert.Equal(t, 0, rl.Status().Failures)
+	assert.Equal(t, "CLOSED", rl.State().String())
+}
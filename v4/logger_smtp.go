@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SMTPAuth is the SMTP authentication mechanism
+type SMTPAuth int
+
+const (
+	SMTPAuthPlain SMTPAuth = iota
+	SMTPAuthLogin
+)
+
+// Default templates of SMTPLogger. Digest subject tells number of events and shows the first one
+const (
+	SMTPSubjectTemplate = `{{if gt .Count 1}}{{.Count}} events on {{.Host}}, first: {{end}}[{{.Event.Level}}] {{.Event.Text}}`
+	SMTPBodyTemplate    = `{{range .Events}}{{$.Format .}}{{end}}{{if .Omitted}}...and {{.Omitted}} more events
+{{end}}`
+)
+
+// smtpMaxSubject is the number of characters subject is truncated to: long event text
+// would make header exceed line length limit of RFC 5322
+const smtpMaxSubject = 200
+
+// SMTPOptions determines how SMTPLogger sends mail
+type SMTPOptions struct {
+	//Addr is host:port of SMTP server, e.g. smtp.example.com:587
+	Addr string
+
+	//Username & Password are used to authenticate in case Username is set
+	Username string
+	Password string
+
+	//Auth is the authentication mechanism, PLAIN by default.
+	//Credentials are sent only over TLS or to localhost
+	Auth SMTPAuth
+
+	//StartTLS makes logger upgrade connection with STARTTLS and fail in case server does not support it
+	StartTLS bool
+
+	//ImplicitTLS makes logger connect with TLS from the start (SMTPS, port 465)
+	ImplicitTLS bool
+
+	//TLSConfig is used for STARTTLS & implicit TLS. Server name is taken from Addr in case it is not set
+	TLSConfig *tls.Config
+
+	//From & To are envelope & header addresses
+	From string
+	To   []string
+
+	//Subject & Body are text/template templates executed with SMTPData.
+	//Defaults are SMTPSubjectTemplate & SMTPBodyTemplate
+	Subject string
+	Body    string
+
+	//MinLevel is the lowest level of events that are mailed (e.g. CRIT). Zero means all events
+	MinLevel Level
+
+	//MaxDigestEvents limits number of events listed in one mail, others are only counted.
+	//Default is 100
+	MaxDigestEvents int
+
+	//Timeout limits whole SMTP session. Default is 30 seconds
+	Timeout time.Duration
+}
+
+// SMTPData is passed to subject & body templates
+type SMTPData struct {
+	//Event is the first event of mail
+	Event Event
+
+	//Events are listed events, Count is the number of all events of mail, Omitted = Count - len(Events)
+	Events  []Event
+	Count   int
+	Omitted int
+
+	Host       string
+	TimeFormat string
+}
+
+// Format returns event formatted with FormatOutput: {{$.Format .}} in templates
+func (d SMTPData) Format(e Event) string {
+	return FormatOutput(e, d.TimeFormat)
+}
+
+// SMTPLogger mails events through SMTP server. It implements IBatchLogger: with batching on
+// (see LogProcessor.SetBatching) all events of a batch are sent in one digest mail, so
+// BatchPolicy.MaxLatency is the digest window.
+type SMTPLogger struct {
+	lTypes  []LogType
+	opts    SMTPOptions
+	host    string
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewSMTP returns SMTPLogger that sends mail through opts.Addr
+func NewSMTP(opts SMTPOptions, lTypes ...LogType) (*SMTPLogger, error) {
+	host, _, err := net.SplitHostPort(opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("[NewSMTP] invalid address %s: %w", opts.Addr, err)
+	}
+	if opts.From == "" || len(opts.To) == 0 {
+		return nil, errors.New("[NewSMTP] sender and recipients are required")
+	}
+	if opts.Auth != SMTPAuthPlain && opts.Auth != SMTPAuthLogin {
+		return nil, fmt.Errorf("[NewSMTP] unsupported auth %d", opts.Auth)
+	}
+	if opts.Subject == "" {
+		opts.Subject = SMTPSubjectTemplate
+	}
+	if opts.Body == "" {
+		opts.Body = SMTPBodyTemplate
+	}
+	if opts.MaxDigestEvents <= 0 {
+		opts.MaxDigestEvents = 100
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	l := &SMTPLogger{lTypes: lTypes, opts: opts, host: host}
+	if l.subject, err = template.New("subject").Parse(opts.Subject); err != nil {
+		return nil, fmt.Errorf("[NewSMTP] %w", err)
+	}
+	if l.body, err = template.New("body").Parse(opts.Body); err != nil {
+		return nil, fmt.Errorf("[NewSMTP] %w", err)
+	}
+
+	return l, nil
+}
+
+// Log mails single event
+func (l *SMTPLogger) Log(e Event, timeFormat string) error {
+	if err := l.mail([]Event{e}, timeFormat); err != nil {
+		return fmt.Errorf("[SMTPLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch mails all events in one digest
+func (l *SMTPLogger) LogBatch(events []Event, timeFormat string) error {
+	if err := l.mail(events, timeFormat); err != nil {
+		return fmt.Errorf("[SMTPLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+func (l *SMTPLogger) mail(events []Event, timeFormat string) error {
+	var mailed []Event
+	for _, e := range events {
+		if e.Level >= l.opts.MinLevel {
+			mailed = append(mailed, e)
+		}
+	}
+	if len(mailed) == 0 {
+		return nil
+	}
+
+	msg, err := l.Message(mailed, timeFormat)
+	if err != nil {
+		return err
+	}
+
+	return l.send(msg)
+}
+
+// Message returns mail of events with headers
+func (l *SMTPLogger) Message(events []Event, timeFormat string) ([]byte, error) {
+	data := SMTPData{Event: events[0], Events: events, Count: len(events), Host: hostName(), TimeFormat: timeFormat}
+	if len(events) > l.opts.MaxDigestEvents {
+		data.Events = events[:l.opts.MaxDigestEvents]
+		data.Omitted = len(events) - l.opts.MaxDigestEvents
+	}
+
+	var subject, body bytes.Buffer
+	if err := l.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("error executing subject template: %w", err)
+	}
+	if err := l.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("error executing body template: %w", err)
+	}
+
+	//Line breaks in subject would let event text inject headers
+	subj := strings.Join(strings.Fields(subject.String()), " ")
+	if r := []rune(subj); len(r) > smtpMaxSubject {
+		subj = string(r[:smtpMaxSubject]) + "…"
+	}
+	//Encoded words are folded one per line, so non-ASCII subject stays within line limit as well
+	subj = strings.ReplaceAll(mime.QEncoding.Encode("utf-8", subj), "?= =?", "?=\r\n =?")
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", l.opts.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(l.opts.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subj)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), data.Host)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write(body.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (l *SMTPLogger) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if l.opts.TLSConfig != nil {
+		cfg = l.opts.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = l.host
+	}
+
+	return cfg
+}
+
+// send delivers msg in one SMTP session
+func (l *SMTPLogger) send(msg []byte) error {
+	d := &net.Dialer{Timeout: l.opts.Timeout}
+	var conn net.Conn
+	var err error
+	if l.opts.ImplicitTLS {
+		conn, err = tls.DialWithDialer(d, "tcp", l.opts.Addr, l.tlsConfig())
+	} else {
+		conn, err = d.Dial("tcp", l.opts.Addr)
+	}
+	if err != nil {
+		return fmt.Errorf("can not connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(l.opts.Timeout))
+
+	c, err := smtp.NewClient(conn, l.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if err = c.Hello(hostName()); err != nil {
+		return err
+	}
+	if l.opts.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err = c.StartTLS(l.tlsConfig()); err != nil {
+			return err
+		}
+	}
+	if l.opts.Username != "" {
+		var auth smtp.Auth
+		if l.opts.Auth == SMTPAuthLogin {
+			auth = &smtpLoginAuth{username: l.opts.Username, password: l.opts.Password, host: l.host}
+		} else {
+			auth = smtp.PlainAuth("", l.opts.Username, l.opts.Password, l.host)
+		}
+		if err = c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(l.opts.From); err != nil {
+		return err
+	}
+	for _, to := range l.opts.To {
+		if err = c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// smtpLoginAuth implements LOGIN mechanism, which net/smtp does not have
+type smtpLoginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *smtpLoginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	//Same rule as smtp.PlainAuth: do not send credentials in clear text to remote hosts
+	local := server.Name == "localhost" || server.Name == "127.0.0.1" || server.Name == "::1"
+	if !server.TLS && !local {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+func (a *smtpLoginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge %q", fromServer)
+	}
+}
+
+// Type returns set of types supported by the logger
+func (l *SMTPLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpMail is a mail received by smtpStub
+type smtpMail struct {
+	from string
+	to   []string
+	auth string
+	tls  bool
+	msg  *mail.Message
+
+	//body is decoded, with \n line breaks
+	body string
+}
+
+// smtpStub is a local stand-in of SMTP server with STARTTLS and PLAIN & LOGIN auth of user:pass
+type smtpStub struct {
+	t    *testing.T
+	ln   net.Listener
+	cert *tls.Certificate
+	mu   sync.Mutex
+	mail []smtpMail
+}
+
+func newSMTPStub(t *testing.T, cert *tls.Certificate) *smtpStub {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpStub{t: t, ln: ln, cert: cert}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpStub) mails() []smtpMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]smtpMail(nil), s.mail...)
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var m smtpMail
+	reply := func(format string, args ...any) { tp.PrintfLine(format, args...) }
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO":
+			if s.cert != nil && !m.tls {
+				reply("250-localhost\r\n250-STARTTLS\r\n250 AUTH PLAIN LOGIN")
+			} else {
+				reply("250-localhost\r\n250 AUTH PLAIN LOGIN")
+			}
+		case "STARTTLS":
+			reply("220 ready")
+			tc := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*s.cert}})
+			if tc.Handshake() != nil {
+				return
+			}
+			conn, tp, m.tls = tc, textproto.NewConn(tc), true
+		case "AUTH":
+			mech, initial, _ := strings.Cut(arg, " ")
+			var user, pass string
+			if mech == "PLAIN" {
+				b, _ := base64.StdEncoding.DecodeString(initial)
+				parts := strings.Split(string(b), "\x00")
+				user, pass = parts[1], parts[2]
+			} else {
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+				l, _ := tp.ReadLine()
+				b, _ := base64.StdEncoding.DecodeString(l)
+				user = string(b)
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+				l, _ = tp.ReadLine()
+				b, _ = base64.StdEncoding.DecodeString(l)
+				pass = string(b)
+			}
+			if user != "user" || pass != "pass" {
+				reply("535 authentication failed")
+				continue
+			}
+			m.auth = mech
+			reply("235 ok")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			m.to = append(m.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 go on")
+			data, err := io.ReadAll(tp.DotReader())
+			require.NoError(s.t, err)
+			m.msg, err = mail.ReadMessage(strings.NewReader(string(data)))
+			require.NoError(s.t, err)
+			body, err := io.ReadAll(quotedprintable.NewReader(m.msg.Body))
+			require.NoError(s.t, err)
+			m.body = strings.ReplaceAll(string(body), "\r\n", "\n")
+			s.mu.Lock()
+			s.mail = append(s.mail, m)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown command")
+		}
+	}
+}
+
+// Single event should be mailed with PLAIN auth after STARTTLS
+func TestSMTPStartTLS(t *testing.T) {
+	cert := selfSignedCert(t)
+	stub := newSMTPStub(t, &cert)
+
+	l, err := NewSMTP(SMTPOptions{
+		Addr:      stub.ln.Addr().String(),
+		Username:  "user",
+		Password:  "pass",
+		StartTLS:  true,
+		TLSConfig: &tls.Config{InsecureSkipVerify: true},
+		From:      "app@example.com",
+		To:        []string{"oncall@example.com", "dev@example.com"},
+		MinLevel:  CRIT,
+	}, Any)
+	require.NoError(t, err)
+
+	//Events below MinLevel are not mailed
+	require.NoError(t, l.Log(Error("just error"), time.RFC3339))
+	e := Critical("disk full\nBcc: evil@example.com").Src(EvsMain)
+	require.NoError(t, l.Log(e, time.RFC3339))
+
+	mails := stub.mails()
+	require.Equal(t, 1, len(mails))
+	m := mails[0]
+	assert.True(t, m.tls)
+	assert.Equal(t, "PLAIN", m.auth)
+	assert.Equal(t, "app@example.com", m.from)
+	assert.Equal(t, []string{"oncall@example.com", "dev@example.com"}, m.to)
+	assert.Equal(t, "[CRITICAL] disk full Bcc: evil@example.com", m.msg.Header.Get("Subject"))
+	assert.Equal(t, "", m.msg.Header.Get("Bcc"))
+	assert.Equal(t, FormatOutput(e, time.RFC3339), m.body)
+}
+
+// Batch should be mailed as one digest with LOGIN auth and custom templates
+func TestSMTPDigest(t *testing.T) {
+	stub := newSMTPStub(t, nil)
+	l, err := NewSMTP(SMTPOptions{
+		Addr:            stub.ln.Addr().String(),
+		Username:        "user",
+		Password:        "pass",
+		Auth:            SMTPAuthLogin,
+		From:            "app@example.com",
+		To:              []string{"oncall@example.com"},
+		Subject:         `Alerts: {{.Count}} ✓`,
+		Body:            `{{range .Events}}{{.Level}} {{.Text}}{{"\n"}}{{end}}+{{.Omitted}}`,
+		MaxDigestEvents: 2,
+	}, Any)
+	require.NoError(t, err)
+
+	p := New(false, "", make(chan error), false, l)
+	p.SetBatching(BatchPolicy{MaxLatency: time.Hour}, l)
+	for i := 0; i < 3; i++ {
+		p.Log(Critical(fmt.Sprint("event", i)))
+	}
+	assert.Equal(t, 0, len(stub.mails()))
+	p.Flush()
+
+	mails := stub.mails()
+	require.Equal(t, 1, len(mails))
+	assert.Equal(t, "LOGIN", mails[0].auth)
+	assert.False(t, mails[0].tls)
+	subject, err := new(mime.WordDecoder).DecodeHeader(mails[0].msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Alerts: 3 ✓", subject)
+	assert.Equal(t, "CRITICAL event0\nCRITICAL event1\n+1\n", mails[0].body)
+}
+
+// Long subject should be truncated and folded, so no header line exceeds RFC 5322 limit
+func TestSMTPLongSubject(t *testing.T) {
+	l, err := NewSMTP(SMTPOptions{Addr: "smtp.example.com:25", From: "a@b", To: []string{"c@d"}, Subject: "{{.Event.Text}}"}, Any)
+	require.NoError(t, err)
+
+	for _, text := range []string{strings.Repeat("x", 2000), strings.Repeat("ж", 2000)} {
+		msg, err := l.Message([]Event{Critical(text)}, time.RFC3339)
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(msg), "\r\n") {
+			assert.LessOrEqual(t, len(line), 998)
+		}
+
+		m, err := mail.ReadMessage(bytes.NewReader(msg))
+		require.NoError(t, err)
+		subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, text[:len(string([]rune(text)[:smtpMaxSubject]))]+"…", subject)
+	}
+}
+
+func TestSMTPOptions(t *testing.T) {
+	_, err := NewSMTP(SMTPOptions{Addr: "smtp.example.com", From: "a@b", To: []string{"c@d"}}, Any)
+	assert.Error(t, err)
+	_, err = NewSMTP(SMTPOptions{Addr: "smtp.example.com:25"}, Any)
+	assert.Error(t, err)
+	_, err = NewSMTP(SMTPOptions{Addr: "smtp.example.com:25", From: "a@b", To: []string{"c@d"}, Subject: "{{"}, Any)
+	assert.Error(t, err)
+
+	//STARTTLS is required, but not offered
+	stub := newSMTPStub(t, nil)
+	l, err := NewSMTP(SMTPOptions{Addr: stub.ln.Addr().String(), StartTLS: true, From: "a@b", To: []string{"c@d"}}, Any)
+	require.NoError(t, err)
+	assert.Error(t, l.Log(Critical("x"), ""))
+
+	//Credentials should not be sent in clear text to remote hosts
+	auth := &smtpLoginAuth{username: "user", password: "pass", host: "smtp.example.com"}
+	_, _, err = auth.Start(&smtp.ServerInfo{Name: "smtp.example.com"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrSocketBufferFull is returned by SocketLogger in case it is disconnected and its buffer has no room for event
+var ErrSocketBufferFull = errors.New("socket buffer is full")
+
+// SocketFraming determines how SocketLogger separates records in stream
+type SocketFraming int
+
+const (
+	//FramingNewline ends every record with \n (trailing line breaks of record are trimmed)
+	FramingNewline SocketFraming = iota
+
+	//FramingLengthPrefix puts record length as 4-byte big-endian integer before record
+	FramingLengthPrefix
+
+	//FramingOctetCounting puts record length as decimal number and space before record (RFC 6587)
+	FramingOctetCounting
+
+	//FramingNone writes records as is. Use it with datagram sockets, where every record is a datagram
+	FramingNone
+)
+
+// SocketOptions determines where and how SocketLogger writes events
+type SocketOptions struct {
+	//Network is "tcp" (default), "udp", "unix" (stream) or "unixgram" (datagram)
+	Network string
+
+	//Addr is host:port or socket path
+	Addr string
+
+	//Framing of records, newline by default
+	Framing SocketFraming
+
+	//Format makes record of event. Default is FormatJSON, use TextFormat to adapt string formatters
+	Format func(e Event, timeFormat string) ([]byte, error)
+
+	//Timeout limits connecting & every write. Default is 5 seconds
+	Timeout time.Duration
+
+	//BufferSize is the max number of bytes of records kept in memory while disconnected.
+	//Default is 1 MiB, negative value disables buffering
+	BufferSize int
+
+	//ReconnectDelay is the delay before first reconnection attempt (1 second by default), each next is
+	//twice as long, but not longer than MaxReconnectDelay (30 seconds by default)
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+}
+
+// TextFormat adapts string formatter (e.g. FormatOutput or FormatCSV) to SocketOptions.Format
+func TextFormat(f func(e Event, timeFormat string) string) func(e Event, timeFormat string) ([]byte, error) {
+	return func(e Event, timeFormat string) ([]byte, error) {
+		return []byte(f(e, timeFormat)), nil
+	}
+}
+
+// SocketLogger writes formatted events into TCP, UDP or unix socket. In case write fails, connection
+// is closed and re-established in background, while records are kept in memory buffer. Buffered records
+// are written right after reconnection in the same order. Record that was partially written before
+// connection broke is sent again, so receiver should be ready for one broken record.
+type SocketLogger struct {
+	lTypes []LogType
+	opts   SocketOptions
+
+	mu           sync.Mutex
+	conn         net.Conn
+	buf          [][]byte
+	bufSize      int
+	dropped      uint64
+	reconnecting bool
+	closed       bool
+	stop         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewSocket returns SocketLogger connected to opts.Addr
+func NewSocket(opts SocketOptions, lTypes ...LogType) (*SocketLogger, error) {
+	if opts.Network == "" {
+		opts.Network = "tcp"
+	}
+	switch opts.Network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("[NewSocket] unsupported network %s", opts.Network)
+	}
+	if opts.Framing < FramingNewline || opts.Framing > FramingNone {
+		return nil, fmt.Errorf("[NewSocket] unsupported framing %d", opts.Framing)
+	}
+	if opts.Format == nil {
+		opts.Format = FormatJSON
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.BufferSize == 0 {
+		opts.BufferSize = 1 << 20
+	}
+	if opts.ReconnectDelay <= 0 {
+		opts.ReconnectDelay = time.Second
+	}
+	if opts.MaxReconnectDelay <= 0 {
+		opts.MaxReconnectDelay = 30 * time.Second
+	}
+
+	l := &SocketLogger{lTypes: lTypes, opts: opts, stop: make(chan struct{})}
+	conn, err := l.dial()
+	if err != nil {
+		return nil, fmt.Errorf("[NewSocket] %w", err)
+	}
+	l.conn = conn
+
+	return l, nil
+}
+
+func (l *SocketLogger) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout(l.opts.Network, l.opts.Addr, l.opts.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("can not connect to %s: %w", l.opts.Addr, err)
+	}
+
+	return conn, nil
+}
+
+// Log writes event into socket. While disconnected event is buffered
+func (l *SocketLogger) Log(e Event, timeFormat string) error {
+	rec, err := l.opts.Format(e, timeFormat)
+	if err != nil {
+		return fmt.Errorf("[SocketLogger][Log] error formatting event: %w", err)
+	}
+	rec = l.Frame(rec)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return fmt.Errorf("[SocketLogger][Log] %w", net.ErrClosed)
+	}
+	if l.conn != nil {
+		if err = l.write(rec); err == nil {
+			return nil
+		}
+		l.disconnect()
+	}
+	if err := l.buffer(rec); err != nil {
+		return fmt.Errorf("[SocketLogger][Log] %w", err)
+	}
+	//Event is not lost, so failed write is not reported
+	return nil
+}
+
+// Frame returns record framed as SocketOptions say
+func (l *SocketLogger) Frame(rec []byte) []byte {
+	switch l.opts.Framing {
+	case FramingNewline:
+		for len(rec) > 0 && (rec[len(rec)-1] == '\n' || rec[len(rec)-1] == '\r') {
+			rec = rec[:len(rec)-1]
+		}
+		return append(rec[:len(rec):len(rec)], '\n')
+	case FramingLengthPrefix:
+		return append(binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(rec)), uint32(len(rec))), rec...)
+	case FramingOctetCounting:
+		return append(append(strconv.AppendInt(nil, int64(len(rec)), 10), ' '), rec...)
+	default:
+		return rec
+	}
+}
+
+// write writes record with deadline. l.mu must be held
+func (l *SocketLogger) write(rec []byte) error {
+	l.conn.SetWriteDeadline(time.Now().Add(l.opts.Timeout))
+	_, err := l.conn.Write(rec)
+
+	return err
+}
+
+// disconnect closes broken connection and starts reconnection. l.mu must be held
+func (l *SocketLogger) disconnect() {
+	l.conn.Close()
+	l.conn = nil
+	if !l.reconnecting {
+		l.reconnecting = true
+		l.wg.Add(1)
+		go l.reconnect()
+	}
+}
+
+// buffer keeps record until connection is re-established. l.mu must be held
+func (l *SocketLogger) buffer(rec []byte) error {
+	if l.opts.BufferSize < 0 {
+		l.dropped++
+		return fmt.Errorf("not connected to %s", l.opts.Addr)
+	}
+	if l.bufSize+len(rec) > l.opts.BufferSize {
+		l.dropped++
+		return ErrSocketBufferFull
+	}
+	l.buf = append(l.buf, rec)
+	l.bufSize += len(rec)
+
+	return nil
+}
+
+// reconnect dials with growing delays until connection is established and buffer is written
+func (l *SocketLogger) reconnect() {
+	defer l.wg.Done()
+
+	delay := l.opts.ReconnectDelay
+	for {
+		t := time.NewTimer(delay)
+		select {
+		case <-l.stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if delay *= 2; delay > l.opts.MaxReconnectDelay {
+			delay = l.opts.MaxReconnectDelay
+		}
+
+		conn, err := l.dial()
+		if err != nil {
+			continue
+		}
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conn = conn
+		if err := l.flushBuffer(); err != nil {
+			l.conn.Close()
+			l.conn = nil
+			l.mu.Unlock()
+			continue
+		}
+		l.reconnecting = false
+		l.mu.Unlock()
+		return
+	}
+}
+
+// flushBuffer writes buffered records in order, keeping the ones not written. l.mu must be held
+func (l *SocketLogger) flushBuffer() error {
+	for len(l.buf) > 0 {
+		if err := l.write(l.buf[0]); err != nil {
+			return err
+		}
+		l.bufSize -= len(l.buf[0])
+		l.buf[0] = nil
+		l.buf = l.buf[1:]
+	}
+	l.buf = nil
+
+	return nil
+}
+
+// Connected returns true in case logger has connection
+func (l *SocketLogger) Connected() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.conn != nil
+}
+
+// Buffered returns number of records waiting for connection
+func (l *SocketLogger) Buffered() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buf)
+}
+
+// Dropped returns number of events lost because buffer was full or disabled
+func (l *SocketLogger) Dropped() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.dropped
+}
+
+// Close stops reconnection and closes connection. Buffered records are lost
+func (l *SocketLogger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.stop)
+	l.mu.Unlock()
+	l.wg.Wait()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+
+	return err
+}
+
+// Type returns set of types supported by the logger
+func (l *SocketLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketFraming(t *testing.T) {
+	cases := []struct {
+		framing SocketFraming
+		want    string
+	}{
+		{FramingNewline, "rec\n"},
+		{FramingLengthPrefix, "\x00\x00\x00\x04rec\n"},
+		{FramingOctetCounting, "4 rec\n"},
+		{FramingNone, "rec\n"},
+	}
+	for _, c := range cases {
+		l := &SocketLogger{opts: SocketOptions{Framing: c.framing}}
+		rec := []byte("rec\n")
+		assert.Equal(t, c.want, string(l.Frame(rec)), "framing %d", c.framing)
+		//Formatter output should not be modified
+		assert.Equal(t, "rec\n", string(rec))
+	}
+
+	_, err := NewSocket(SocketOptions{Network: "ip", Addr: "localhost"}, Any)
+	assert.Error(t, err)
+	_, err = NewSocket(SocketOptions{Addr: "localhost:1", Framing: 10}, Any)
+	assert.Error(t, err)
+}
+
+// Records should be buffered while disconnected and written in order after reconnection
+func TestSocketReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	l, err := NewSocket(SocketOptions{
+		Addr:           ln.Addr().String(),
+		Framing:        FramingOctetCounting,
+		Format:         TextFormat(func(e Event, tf string) string { return e.Text }),
+		ReconnectDelay: 10 * time.Millisecond,
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+	conn1, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn1.Close()
+
+	require.NoError(t, l.Log(Info("event1"), ""))
+	r := bufio.NewReader(conn1)
+	assert.Equal(t, "6 event1", readN(t, r, 8))
+
+	//Broken connection: events are kept until logger reconnects
+	l.mu.Lock()
+	l.conn.Close()
+	l.mu.Unlock()
+	require.NoError(t, l.Log(Info("event2"), ""))
+	require.NoError(t, l.Log(Info("event3"), ""))
+	assert.False(t, l.Connected())
+
+	conn2, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn2.Close()
+	r = bufio.NewReader(conn2)
+	assert.Equal(t, "6 event26 event3", readN(t, r, 16))
+	assert.Eventually(t, l.Connected, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, l.Buffered())
+
+	require.NoError(t, l.Log(Info("event4"), ""))
+	assert.Equal(t, "6 event4", readN(t, r, 8))
+}
+
+func readN(t *testing.T, r *bufio.Reader, n int) string {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	require.NoError(t, err)
+
+	return string(b)
+}
+
+// Events that do not fit into buffer should be dropped with error
+func TestSocketBufferFull(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	l, err := NewSocket(SocketOptions{Addr: ln.Addr().String(), BufferSize: 100, ReconnectDelay: time.Hour}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+	ln.Close()
+
+	l.mu.Lock()
+	l.conn.Close()
+	l.mu.Unlock()
+	e := Info("x").SetID("1")
+	require.NoError(t, l.Log(e, ""))
+	err = l.Log(e, "")
+	assert.True(t, errors.Is(err, ErrSocketBufferFull))
+	assert.Equal(t, 1, l.Buffered())
+	assert.Equal(t, uint64(1), l.Dropped())
+
+	//Close should stop reconnection
+	require.NoError(t, l.Close())
+	assert.Error(t, l.Log(e, ""))
+}
+
+// Every record should be a datagram on unixgram socket
+func TestSocketUnixgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix datagram sockets")
+	}
+	path := filepath.Join(t.TempDir(), "log.sock")
+	srv, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	l, err := NewSocket(SocketOptions{Network: "unixgram", Addr: path, Framing: FramingNone, Format: TextFormat(FormatCSV)}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+
+	e := Warning("disk").Src(EvsMain).SetID("7")
+	require.NoError(t, l.Log(e, "2006"))
+	require.NoError(t, l.Log(e.SetText("net"), "2006"))
+
+	buf := make([]byte, 1024)
+	for _, want := range []string{FormatCSV(e, "2006"), FormatCSV(e.SetText("net"), "2006")} {
+		srv.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := srv.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(buf[:n]))
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SpoolLogger wraps any ILogger to save events that logger failed to process into Spool
+// and replay them in the original order once logger works again.
+//
+// While spool is not empty, new events are appended to it after an attempt to replay,
+// so the wrapped logger always receives events in order. Log and Flush calls are serialized
+// for the same reason.
+type SpoolLogger struct {
+	lg    ILogger
+	spool *Spool
+
+	mu sync.Mutex
+}
+
+// NewSpooled returns SpoolLogger that saves events lg failed to process into s
+func NewSpooled(lg ILogger, s *Spool) *SpoolLogger {
+	return &SpoolLogger{lg: lg, spool: s}
+}
+
+// Log replays spooled events (if any) and passes e to the wrapped logger.
+// In case logger fails, event is spooled and no error is returned.
+// Error is returned only if event could not be spooled or spool has corrupted data.
+func (l *SpoolLogger) Log(e Event, timeFormat string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var corrErr error
+	if !l.spool.Empty() {
+		_, err := l.spool.Replay(l.lg.Log)
+		if errors.Is(err, ErrSpoolCorrupted) {
+			corrErr = err
+		} else if err != nil {
+			//Logger is still down: keep the order
+			return l.append(e, timeFormat)
+		}
+	}
+
+	if err := l.lg.Log(e, timeFormat); err != nil {
+		if sErr := l.append(e, timeFormat); sErr != nil {
+			return sErr
+		}
+	}
+
+	if corrErr != nil {
+		return fmt.Errorf("[SpoolLogger][Log] %w", corrErr)
+	}
+
+	return nil
+}
+
+func (l *SpoolLogger) append(e Event, timeFormat string) error {
+	if err := l.spool.Append(e, timeFormat); err != nil {
+		return fmt.Errorf("[SpoolLogger][Log] event lost: %w", err)
+	}
+
+	return nil
+}
+
+// Flush replays spooled events to the wrapped logger. It can be called periodically
+// to deliver spooled events even when no new events are logged.
+func (l *SpoolLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.spool.Replay(l.lg.Log); err != nil {
+		return fmt.Errorf("[SpoolLogger][Flush] %w", err)
+	}
+
+	return nil
+}
+
+// Type returns set of types supported by the wrapped logger
+func (l *SpoolLogger) Type() []LogType { return l.lg.Type() }
+
+// Spool returns spool used by the logger
+func (l *SpoolLogger) Spool() *Spool { return l.spool }
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchLogger records events and fails while down is true
+type switchLogger struct {
+	down   bool
+	logged []string
+}
+
+func (l *switchLogger) Log(e Event, timeFormat string) error {
+	if l.down {
+		return fmt.Errorf("logger is down")
+	}
+	l.logged = append(l.logged, e.Text)
+	return nil
+}
+func (l *switchLogger) Type() []LogType { return []LogType{Any} }
+
+// Events should be spooled while logger is down and delivered in order after recovery
+func TestLoggerSpoolLog(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	lg := &switchLogger{}
+	sl := NewSpooled(lg, s)
+	assert.Equal(t, []LogType{Any}, sl.Type())
+
+	require.NoError(t, sl.Log(Info("event0"), ""))
+	lg.down = true
+	require.NoError(t, sl.Log(Info("event1"), ""))
+	require.NoError(t, sl.Log(Info("event2"), ""))
+	assert.Equal(t, false, s.Empty())
+
+	lg.down = false
+	require.NoError(t, sl.Log(Info("event3"), ""))
+	assert.Equal(t, []string{"event0", "event1", "event2", "event3"}, lg.logged)
+	assert.Equal(t, true, s.Empty())
+
+	lg.down = true
+	require.NoError(t, sl.Log(Info("event4"), ""))
+	lg.down = false
+	require.NoError(t, sl.Flush())
+	assert.Equal(t, "event4", lg.logged[4])
+}
+
+// Concurrent callers should not break order of events: spooled ones go first, events of every caller keep their order
+func TestLoggerSpoolConcurrent(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	lg := &switchLogger{down: true}
+	sl := NewSpooled(lg, s)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, sl.Log(Info(fmt.Sprintf("spooled%d", i)), ""))
+	}
+	lg.down = false
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				assert.NoError(t, sl.Log(Info(fmt.Sprintf("%d-%02d", g, i)), ""))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	require.Equal(t, 110, len(lg.logged))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, fmt.Sprintf("spooled%d", i), lg.logged[i])
+	}
+	last := map[string]string{}
+	for _, text := range lg.logged[10:] {
+		g := text[:1]
+		assert.Greater(t, text, last[g])
+		last[g] = text
+	}
+	assert.Equal(t, true, s.Empty())
+}
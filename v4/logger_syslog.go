@@ -0,0 +1,275 @@
+package logger
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat determines message format of SyslogLogger
+type SyslogFormat int
+
+const (
+	//RFC5424 is the modern syslog format with structured data carrying event fields
+	RFC5424 SyslogFormat = iota
+
+	//RFC3164 is the legacy BSD syslog format
+	RFC3164
+)
+
+// SyslogFacility is the syslog facility code of messages
+type SyslogFacility int
+
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	FacilityLocal0 SyslogFacility = iota + 4
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogOptions determines where and how SyslogLogger sends messages
+type SyslogOptions struct {
+	//Network is one of "unixgram", "udp", "tcp" or "tls". Default is "unixgram"
+	Network string
+
+	//Addr is the socket path for unixgram (default /dev/log) or host:port for others
+	Addr string
+
+	//TLSConfig is used for "tls" network
+	TLSConfig *tls.Config
+
+	//Format is RFC5424 by default
+	Format SyslogFormat
+
+	//Facility of messages. Kern facility is reserved for kernel, so zero value means FacilityUser
+	Facility SyslogFacility
+
+	//AppName & Hostname are put into every message. Default are program name & host name
+	AppName  string
+	Hostname string
+
+	//SDID is the ID of RFC 5424 structured data element with event fields. Default is lazyevent@32473
+	SDID string
+
+	//Timeout limits connecting & writing. Default is 5 seconds
+	Timeout time.Duration
+}
+
+// SyslogLogger sends events to syslog server. Messages are sent with one datagram each over
+// unixgram & udp, and with octet-counting framing (RFC 6587) over tcp & tls.
+// Connection is re-established in case write fails.
+type SyslogLogger struct {
+	lTypes []LogType
+	opts   SyslogOptions
+	pid    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog returns SyslogLogger connected to syslog server according to opts
+func NewSyslog(opts SyslogOptions, lTypes ...LogType) (*SyslogLogger, error) {
+	if opts.Network == "" {
+		opts.Network = "unixgram"
+	}
+	if opts.Addr == "" && opts.Network == "unixgram" {
+		opts.Addr = "/dev/log"
+	}
+	if opts.Facility == FacilityKern {
+		opts.Facility = FacilityUser
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname = hostName()
+	}
+	if opts.SDID == "" {
+		opts.SDID = "lazyevent@32473"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	switch opts.Network {
+	case "unixgram", "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("[NewSyslog] unsupported network %s", opts.Network)
+	}
+
+	l := &SyslogLogger{lTypes: lTypes, opts: opts, pid: strconv.Itoa(os.Getpid())}
+	if err := l.connect(); err != nil {
+		return nil, fmt.Errorf("[NewSyslog] %w", err)
+	}
+
+	return l, nil
+}
+
+func (l *SyslogLogger) connect() error {
+	d := &net.Dialer{Timeout: l.opts.Timeout}
+	var err error
+	if l.opts.Network == "tls" {
+		l.conn, err = tls.DialWithDialer(d, "tcp", l.opts.Addr, l.opts.TLSConfig)
+	} else {
+		l.conn, err = d.Dial(l.opts.Network, l.opts.Addr)
+	}
+	if err != nil {
+		return fmt.Errorf("can not connect to syslog: %w", err)
+	}
+
+	return nil
+}
+
+// Log sends event to syslog. timeFormat is unused: syslog formats define their own timestamps
+func (l *SyslogLogger) Log(e Event, timeFormat string) error {
+	msg := l.Format(e)
+	if l.opts.Network == "tcp" || l.opts.Network == "tls" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	//Retry once with new connection: server may have been restarted
+	err := l.write(msg)
+	if err != nil {
+		if cErr := l.connect(); cErr != nil {
+			return fmt.Errorf("[SyslogLogger][Log] %w", CompositeError{Errors: []error{err, cErr}})
+		}
+		err = l.write(msg)
+	}
+	if err != nil {
+		return fmt.Errorf("[SyslogLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+func (l *SyslogLogger) write(msg string) error {
+	if l.conn == nil {
+		return net.ErrClosed
+	}
+	l.conn.SetWriteDeadline(time.Now().Add(l.opts.Timeout))
+	if _, err := l.conn.Write([]byte(msg)); err != nil {
+		l.conn.Close()
+		l.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// Format returns syslog message of e without transport framing
+func (l *SyslogLogger) Format(e Event) string {
+	pri := int(l.opts.Facility)*8 + SyslogSeverity(e.Level)
+	if l.opts.Format == RFC3164 {
+		return fmt.Sprintf("<%d>%s %s %s[%s]: %s",
+			pri, e.Time.Format(time.Stamp), l.opts.Hostname, l.opts.AppName, l.pid, e.Text)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s - %s %s",
+		pri,
+		e.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(l.opts.Hostname),
+		syslogHeaderField(l.opts.AppName),
+		l.pid,
+		l.structuredData(e),
+		e.Text,
+	)
+}
+
+// structuredData returns RFC 5424 SD element with event fields
+func (l *SyslogLogger) structuredData(e Event) string {
+	var b strings.Builder
+	b.WriteString("[" + l.opts.SDID)
+	param := func(name, val string) {
+		if val != "" {
+			b.WriteString(" " + name + "=\"" + syslogParamValue(val) + "\"")
+		}
+	}
+	param("id", e.ID)
+	param("level", e.Level.String())
+	param("source", e.Source.Text)
+	param("type", strconv.Itoa(int(e.Type)))
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// SyslogSeverity maps event level to syslog severity
+func SyslogSeverity(l Level) int {
+	switch l {
+	case FATAL:
+		return 0 //emerg
+	case PANIC:
+		return 1 //alert
+	case CRIT:
+		return 2 //crit
+	case ERR:
+		return 3 //err
+	case WARN:
+		return 4 //warning
+	case NOTE:
+		return 5 //notice
+	default:
+		return 6 //info
+	}
+}
+
+// syslogHeaderField returns s as printable ASCII without spaces or "-" in case s is empty
+func syslogHeaderField(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// syslogParamValue escapes '"', '\' and ']' in SD param value
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// Close closes connection to syslog
+func (l *SyslogLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+
+	return err
+}
+
+// Type returns set of types supported by the logger
+func (l *SyslogLogger) Type() []LogType { return l.lTypes }
@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var syslogTestTime = time.Date(2026, 10, 18, 9, 5, 3, 123456000, time.UTC)
+
+func TestSyslogFormat(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udp.Close()
+
+	l, err := NewSyslog(SyslogOptions{
+		Network:  "udp",
+		Addr:     udp.LocalAddr().String(),
+		Facility: FacilityLocal3,
+		AppName:  "my app",
+		Hostname: "web1",
+	}, Any)
+	require.NoError(t, err)
+	defer l.Close()
+	pid := strconv.Itoa(os.Getpid())
+
+	e := Error(`broken "quote" ]`).Src(EvsMain).SetID("42")
+	e.Time = syslogTestTime
+	//local3 * 8 + err
+	assert.Equal(t, `<155>1 2026-10-18T09:05:03.123456Z web1 my_app `+pid+` - [lazyevent@32473 id="42" level="ERROR" source="MAIN" type="0"] broken "quote" ]`, l.Format(e))
+
+	l.opts.Format = RFC3164
+	assert.Equal(t, "<155>Oct 18 09:05:03 web1 my app["+pid+`]: broken "quote" ]`, l.Format(e))
+
+	assert.Equal(t, 0, SyslogSeverity(FATAL))
+	assert.Equal(t, 5, SyslogSeverity(NOTE))
+	assert.Equal(t, 6, SyslogSeverity(INFO))
+	assert.Equal(t, FacilityUser, mustSyslog(t, SyslogOptions{Network: "udp", Addr: udp.LocalAddr().String()}).opts.Facility)
+}
+
+func mustSyslog(t *testing.T, opts SyslogOptions) *SyslogLogger {
+	l, err := NewSyslog(opts, Any)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	return l
+}
+
+// Every event should be sent as separate datagram
+func TestSyslogUDP(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udp.Close()
+
+	l := mustSyslog(t, SyslogOptions{Network: "udp", Addr: udp.LocalAddr().String()})
+	require.NoError(t, l.Log(Warning("event1"), ""))
+	require.NoError(t, l.Log(Info("event2"), ""))
+
+	buf := make([]byte, 2048)
+	udp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, want := range []string{"<12>1 ", "<14>1 "} {
+		n, _, err := udp.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(buf[:n]), want), string(buf[:n]))
+	}
+}
+
+func TestSyslogUnixgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix datagram sockets")
+	}
+	//Socket path should be short, so temp dir of the test may not fit
+	dir, err := os.MkdirTemp("", "syslog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sock := filepath.Join(dir, "log")
+	conn, err := net.ListenPacket("unixgram", sock)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	l := mustSyslog(t, SyslogOptions{Addr: sock, Format: RFC3164})
+	require.NoError(t, l.Log(Critical("event1"), ""))
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "<10>"))
+	assert.True(t, strings.HasSuffix(string(buf[:n]), "]: event1"))
+}
+
+// readOctetCounted reads one RFC 6587 octet-counted frame
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	size, err := r.ReadString(' ')
+	require.NoError(t, err)
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	require.NoError(t, err)
+	msg := make([]byte, n)
+	_, err = io.ReadFull(r, msg)
+	require.NoError(t, err)
+
+	return string(msg)
+}
+
+// Messages should be framed by octet counting and connection re-established after failure
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	l := mustSyslog(t, SyslogOptions{Network: "tcp", Addr: ln.Addr().String()})
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(Info("multi\nline"), ""))
+	require.NoError(t, l.Log(Info("event2"), ""))
+	r := bufio.NewReader(conn)
+	assert.True(t, strings.HasSuffix(readOctetCounted(t, r), "] multi\nline"))
+	assert.True(t, strings.HasSuffix(readOctetCounted(t, r), "] event2"))
+
+	//Server drops connection: logger should notice it and reconnect
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		if err := l.Log(Info("after"), ""); err != nil {
+			return false
+		}
+		ln.(*net.TCPListener).SetDeadline(time.Now().Add(50 * time.Millisecond))
+		conn, err = ln.Accept()
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer conn.Close()
+	assert.True(t, strings.HasSuffix(readOctetCounted(t, bufio.NewReader(conn)), "] after"))
+}
+
+func TestSyslogTLS(t *testing.T) {
+	cert := selfSignedCert(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		//Handshake is made on first read, but client waits for it while connecting
+		conn, err := ln.Accept()
+		if err == nil && conn.(*tls.Conn).Handshake() == nil {
+			accepted <- conn
+		}
+	}()
+
+	l := mustSyslog(t, SyslogOptions{
+		Network:   "tls",
+		Addr:      ln.Addr().String(),
+		TLSConfig: &tls.Config{RootCAs: pool, ServerName: "localhost"},
+	})
+	require.NoError(t, l.Log(Note("secure"), ""))
+
+	conn := <-accepted
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msg := readOctetCounted(t, bufio.NewReader(conn))
+	assert.True(t, strings.HasPrefix(msg, "<13>1 "))
+	assert.True(t, strings.HasSuffix(msg, "] secure"))
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
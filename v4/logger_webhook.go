@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// WebhookOptions determines where and how WebhookLogger sends events
+type WebhookOptions struct {
+	HTTPOptions
+
+	//URL to send events to
+	URL string
+
+	//Method is POST by default
+	Method string
+
+	//Template is a text/template of request body (see WebhookData), e.g. for chat webhooks:
+	//	{"text": {{json .Event.Text}}}
+	//Function json encodes any value as JSON. Empty template makes JSON object of single event
+	//(same as JSON file logger) or JSON array of batched events
+	Template string
+
+	//ContentType is application/json by default
+	ContentType string
+}
+
+// WebhookData is passed to WebhookOptions.Template. Event is the first (or the only) event,
+// Events are all events of a batch. Raw are original events in case template needs more than text fields
+type WebhookData struct {
+	Event  LogPatternJSON
+	Events []LogPatternJSON
+	Raw    []Event
+}
+
+// WebhookLogger sends events to HTTP endpoint. It implements IBatchLogger, so with batching on
+// (see LogProcessor.SetBatching) several events are sent in one request.
+type WebhookLogger struct {
+	lTypes []LogType
+	opts   WebhookOptions
+	tmpl   *template.Template
+	sender *httpSender
+}
+
+// NewWebhook returns WebhookLogger that sends events to opts.URL
+func NewWebhook(opts WebhookOptions, lTypes ...LogType) (*WebhookLogger, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("[NewWebhook] URL is empty")
+	}
+	if opts.Method == "" {
+		opts.Method = "POST"
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/json"
+	}
+
+	l := &WebhookLogger{lTypes: lTypes, opts: opts, sender: newHTTPSender(opts.HTTPOptions)}
+	if opts.Template != "" {
+		t, err := template.New("webhook").Funcs(template.FuncMap{"json": webhookJSON}).Parse(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("[NewWebhook] %w", err)
+		}
+		l.tmpl = t
+	}
+
+	return l, nil
+}
+
+func webhookJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+
+	return string(b), err
+}
+
+// Log sends single event
+func (l *WebhookLogger) Log(e Event, timeFormat string) error {
+	if err := l.send([]Event{e}, timeFormat, false); err != nil {
+		return fmt.Errorf("[WebhookLogger][Log] %w", err)
+	}
+
+	return nil
+}
+
+// LogBatch sends all events in one request
+func (l *WebhookLogger) LogBatch(events []Event, timeFormat string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := l.send(events, timeFormat, true); err != nil {
+		return fmt.Errorf("[WebhookLogger][LogBatch] %w", err)
+	}
+
+	return nil
+}
+
+func (l *WebhookLogger) send(events []Event, timeFormat string, batch bool) error {
+	body, err := l.Body(events, timeFormat, batch)
+	if err != nil {
+		return err
+	}
+	_, err = l.sender.send(l.opts.Method, l.opts.URL, map[string]string{"Content-Type": l.opts.ContentType}, body)
+
+	return err
+}
+
+// Body returns request body of events. batch makes default body a JSON array even for single event
+func (l *WebhookLogger) Body(events []Event, timeFormat string, batch bool) ([]byte, error) {
+	records := make([]LogPatternJSON, len(events))
+	for i, e := range events {
+		records[i] = LogPatternJSON{
+			ID:     e.ID,
+			Time:   e.Time.Format(timeFormat),
+			Level:  fmt.Sprint(e.Level),
+			Source: e.Source.String(),
+			Text:   e.Text,
+		}
+	}
+
+	if l.tmpl == nil {
+		if batch {
+			return json.Marshal(records)
+		}
+		return json.Marshal(records[0])
+	}
+
+	var b bytes.Buffer
+	if err := l.tmpl.Execute(&b, WebhookData{Event: records[0], Events: records, Raw: events}); err != nil {
+		return nil, fmt.Errorf("error executing template: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// Close interrupts requests & retry delays in progress, they fail with ErrSenderClosed.
+// Logger should not be used after Close
+func (l *WebhookLogger) Close() error {
+	l.sender.close()
+
+	return nil
+}
+
+// Type returns set of types supported by the logger
+func (l *WebhookLogger) Type() []LogType { return l.lTypes }
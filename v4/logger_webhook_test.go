@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookStub records requests and responds with statuses one by one (200 after they run out)
+type webhookStub struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int
+	retryIn  string
+}
+
+func (s *webhookStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bodies = append(s.bodies, string(b))
+	s.headers = append(s.headers, r.Header.Clone())
+	if len(s.statuses) > 0 {
+		code := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		if s.retryIn != "" {
+			w.Header().Set("Retry-After", s.retryIn)
+		}
+		w.WriteHeader(code)
+		w.Write([]byte("try later"))
+	}
+}
+
+func TestWebhookBody(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	l, err := NewWebhook(WebhookOptions{
+		URL:         srv.URL,
+		HTTPOptions: HTTPOptions{Headers: map[string]string{"X-App": "test"}, BearerToken: "secret"},
+	}, Any)
+	require.NoError(t, err)
+
+	e := Info("event1").SetID("1")
+	e.Time = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, l.Log(e, time.RFC3339))
+	require.NoError(t, l.LogBatch([]Event{e, e.SetText("event2")}, time.RFC3339))
+
+	require.Equal(t, 2, len(stub.bodies))
+	assert.JSONEq(t, `{"id":"1","time":"2026-10-18T12:00:00Z","level":"INFO","source":"","text":"event1"}`, stub.bodies[0])
+	assert.JSONEq(t, `[{"id":"1","time":"2026-10-18T12:00:00Z","level":"INFO","source":"","text":"event1"},
+		{"id":"1","time":"2026-10-18T12:00:00Z","level":"INFO","source":"","text":"event2"}]`, stub.bodies[1])
+	assert.Equal(t, "application/json", stub.headers[0].Get("Content-Type"))
+	assert.Equal(t, "test", stub.headers[0].Get("X-App"))
+	assert.Equal(t, "Bearer secret", stub.headers[0].Get("Authorization"))
+}
+
+// Chat-style payload should be made by template
+func TestWebhookTemplate(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	l, err := NewWebhook(WebhookOptions{
+		URL:         srv.URL,
+		Template:    `{"text": {{json (printf "%s: %s" .Event.Level .Event.Text)}}, "count": {{len .Events}}}`,
+		HTTPOptions: HTTPOptions{Username: "user", Password: "pass"},
+	}, Any)
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(Error(`disk "full"`), time.RFC3339))
+	assert.JSONEq(t, `{"text": "ERROR: disk \"full\"", "count": 1}`, stub.bodies[0])
+	user, pass, ok := (&http.Request{Header: stub.headers[0]}).BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", pass)
+
+	_, err = NewWebhook(WebhookOptions{URL: srv.URL, Template: "{{"}, Any)
+	assert.Error(t, err)
+	_, err = NewWebhook(WebhookOptions{}, Any)
+	assert.Error(t, err)
+}
+
+// 5xx & 429 should be retried respecting Retry-After, other errors should not
+func TestWebhookRetry(t *testing.T) {
+	stub := &webhookStub{statuses: []int{503, 429}, retryIn: "7"}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	l, err := NewWebhook(WebhookOptions{URL: srv.URL, HTTPOptions: HTTPOptions{MaxRetries: 2}}, Any)
+	require.NoError(t, err)
+	var waits []time.Duration
+	l.sender.sleep = func(d time.Duration) bool { waits = append(waits, d); return true }
+
+	require.NoError(t, l.Log(Info("event1"), time.RFC3339))
+	assert.Equal(t, 3, len(stub.bodies))
+	assert.Equal(t, []time.Duration{7 * time.Second, 7 * time.Second}, waits)
+
+	//Without Retry-After delay should grow exponentially, but retries are limited
+	stub.statuses, stub.retryIn, waits = []int{500, 502, 503}, "", nil
+	err = l.Log(Info("event2"), time.RFC3339)
+	var se *HTTPStatusError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 503, se.StatusCode)
+	assert.Equal(t, "try later", se.Body)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+
+	stub.statuses, waits = []int{400}, nil
+	stub.bodies = nil
+	err = l.Log(Info("event3"), time.RFC3339)
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, 400, se.StatusCode)
+	assert.Equal(t, 1, len(stub.bodies))
+	assert.Nil(t, waits)
+}
+
+// Requests that can never succeed should not be retried, Close should interrupt retry delays
+func TestWebhookRetryStop(t *testing.T) {
+	l, err := NewWebhook(WebhookOptions{URL: "http://[::1", HTTPOptions: HTTPOptions{MaxRetries: 3}}, Any)
+	require.NoError(t, err)
+	var waits []time.Duration
+	l.sender.sleep = func(d time.Duration) bool { waits = append(waits, d); return true }
+	assert.Error(t, l.Log(Info("event1"), time.RFC3339))
+	assert.Nil(t, waits)
+
+	stub := &webhookStub{statuses: []int{503, 503, 503, 503}}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+	l, err = NewWebhook(WebhookOptions{URL: srv.URL, HTTPOptions: HTTPOptions{MaxRetries: 3, RetryDelay: time.Hour}}, Any)
+	require.NoError(t, err)
+
+	res := make(chan error)
+	go func() { res <- l.Log(Info("event2"), time.RFC3339) }()
+	assert.Eventually(t, func() bool {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		return len(stub.bodies) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, l.Close())
+	select {
+	case err := <-res:
+		assert.ErrorIs(t, err, ErrSenderClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Close did not interrupt retry delay")
+	}
+	assert.ErrorIs(t, l.Log(Info("event3"), time.RFC3339), ErrSenderClosed)
+}
+
+// Successful response larger than limit should fail without retries
+func TestHTTPSenderResponseLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, maxResponseBody+1))
+	}))
+	defer srv.Close()
+
+	s := newHTTPSender(HTTPOptions{MaxRetries: 3})
+	var waits []time.Duration
+	s.sleep = func(d time.Duration) bool { waits = append(waits, d); return true }
+	_, err := s.send("POST", srv.URL, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, waits)
+}
+
+func TestWebhookTLS(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewTLSServer(stub)
+	defer srv.Close()
+
+	_, err := mustWebhook(t, srv.URL, nil).sender.send("POST", srv.URL, nil, nil)
+	assert.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	l := mustWebhook(t, srv.URL, &tls.Config{RootCAs: pool})
+	require.NoError(t, l.Log(Info("secure"), time.RFC3339))
+	assert.Equal(t, 1, len(stub.bodies))
+}
+
+func mustWebhook(t *testing.T, url string, tc *tls.Config) *WebhookLogger {
+	l, err := NewWebhook(WebhookOptions{URL: url, HTTPOptions: HTTPOptions{TLSConfig: tc}}, Any)
+	require.NoError(t, err)
+
+	return l
+}
+
+// Batched events should be sent in one request by LogProcessor
+func TestWebhookBatching(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	l := mustWebhook(t, srv.URL, nil)
+	p := New(false, time.RFC3339, make(chan error), false, l)
+	p.SetBatching(BatchPolicy{MaxEvents: 3})
+	p.Log(Info("1"))
+	p.Log(Info("2"))
+	p.Log(Info("3"))
+	require.NoError(t, p.Close())
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	require.Equal(t, 1, len(stub.bodies))
+	assert.Contains(t, stub.bodies[0], `"text":"3"`)
+}
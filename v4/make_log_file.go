@@ -1,28 +1,141 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/lazybark/go-helpers/fsw"
 )
 
-// makeLogFile creates new log file with addition of current date (Y_M_D_H_M_S)
-func makeLogFile(path string, truncate bool, ext string) (*os.File, error) {
-	now := time.Now()
-	f, err := fsw.MakePathToFile(
-		fmt.Sprintf("%s-%d_%d_%d_%d_%d_%d.%s",
-			path,
-			now.Year(), now.Month(), now.Day(),
-			now.Hour(), now.Minute(), now.Second(),
-			ext,
-		),
-		truncate,
+// logFileName returns default name of log file: path with addition of date (Y_M_D_H_M_S) and extension
+func logFileName(path string, ext string, t time.Time) string {
+	return fmt.Sprintf("%s-%d_%d_%d_%d_%d_%d.%s",
+		path,
+		t.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(),
+		ext,
 	)
+}
+
+// openLogFile opens file at name for appending, creating it and its directory with perms if needed
+func openLogFile(name string, truncate bool, perms filePerms) (IFile, error) {
+	if err := perms.mkdirAll(filepath.Dir(name)); err != nil {
+		return nil, fmt.Errorf("[openLogFile] can not make dir: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if truncate {
+		flags |= os.O_TRUNC
+	}
+	_, statErr := os.Stat(name)
+	f, err := os.OpenFile(name, flags, perms.fileMode())
+	if err != nil {
+		return nil, fmt.Errorf("[openLogFile] can not open file: %w", err)
+	}
+
+	//Mode & group are set only for new files, existing ones may be configured by admin
+	if errors.Is(statErr, os.ErrNotExist) {
+		if err := perms.apply(name, perms.file); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("[openLogFile] %w", err)
+		}
+	}
+
+	return f, nil
+}
+
+// FileNameFromTemplate returns log file name made of template tmpl. Template can contain:
+//
+//	{path}  - path passed to logger
+//	{ext}   - default file extension of logger (log, csv, json)
+//	{date}  - date as 2006-01-02
+//	{time}  - time as 15-04-05
+//	{YYYY}, {MM}, {DD}, {hh}, {mm}, {ss} - parts of date & time
+//	{pid}   - ID of current process
+//	{host}  - host name
+//
+// E.g. "{path}-{date}.{ext}" makes "app-2026-10-18.log" for path "app".
+func FileNameFromTemplate(tmpl string, path string, ext string, t time.Time) string {
+	r := strings.NewReplacer(
+		"{path}", path,
+		"{ext}", ext,
+		"{date}", t.Format("2006-01-02"),
+		"{time}", t.Format("15-04-05"),
+		"{YYYY}", t.Format("2006"),
+		"{MM}", t.Format("01"),
+		"{DD}", t.Format("02"),
+		"{hh}", t.Format("15"),
+		"{mm}", t.Format("04"),
+		"{ss}", t.Format("05"),
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{host}", hostName(),
+	)
+
+	return r.Replace(tmpl)
+}
+
+// fileNameTimeTokens are FileNameFromTemplate tokens that change with time and patterns of their values
+var fileNameTimeTokens = []struct{ token, pattern string }{
+	{"{date}", `\d+-\d{2}-\d{2}`},
+	{"{time}", `\d{2}-\d{2}-\d{2}`},
+	{"{YYYY}", `\d+`},
+	{"{MM}", `\d{2}`},
+	{"{DD}", `\d{2}`},
+	{"{hh}", `\d{2}`},
+	{"{mm}", `\d{2}`},
+	{"{ss}", `\d{2}`},
+}
+
+// fileNamePattern returns regexp that matches absolute names of all files made of template tmpl
+// (or default names in case tmpl is empty) at any time
+func fileNamePattern(tmpl string, path string, ext string) (*regexp.Regexp, error) {
+	//Time tokens are replaced with markers that survive path cleaning, then with patterns
+	marker := func(i int) string { return "\x00" + strconv.Itoa(i) + "\x00" }
+	var name string
+	if tmpl == "" {
+		n := marker(len(fileNameTimeTokens))
+		name = path + "-" + strings.Repeat(n+"_", 5) + n + "." + ext
+	} else {
+		pairs := []string{"{path}", path, "{ext}", ext, "{pid}", strconv.Itoa(os.Getpid()), "{host}", hostName()}
+		for i, t := range fileNameTimeTokens {
+			pairs = append(pairs, t.token, marker(i))
+		}
+		name = strings.NewReplacer(pairs...).Replace(tmpl)
+	}
+	abs, err := filepath.Abs(name)
 	if err != nil {
-		return nil, fmt.Errorf("[makeLogFile] error making log path: %w", err)
+		return nil, err
+	}
+
+	pairs := []string{marker(len(fileNameTimeTokens)), `\d+`}
+	for i, t := range fileNameTimeTokens {
+		pairs = append(pairs, marker(i), t.pattern)
+	}
+
+	return regexp.Compile("^" + strings.NewReplacer(pairs...).Replace(regexp.QuoteMeta(abs)) + "$")
+}
+
+// hostName returns host name or "localhost" in case it's unknown
+func hostName() string {
+	h, err := os.Hostname()
+	if err != nil || h == "" {
+		return "localhost"
+	}
+
+	return h
+}
+
+// isEmptyFile returns true in case f has no data or its size is unknown
+func isEmptyFile(f IFile) bool {
+	st, ok := f.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return true
 	}
+	info, err := st.Stat()
 
-	return f, err
+	return err != nil || info.Size() == 0
 }
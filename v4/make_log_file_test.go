@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Log files should be opened write-only in append mode, with missing directories created
+func TestOpenLogFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	f, err := openLogFile(name, false, filePerms{gid: -1})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("event1\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	f, err = openLogFile(name, false, filePerms{gid: -1})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("event2\n"))
+	require.NoError(t, err)
+	_, err = f.(*os.File).Read(make([]byte, 1))
+	assert.Error(t, err)
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "event1\nevent2\n", string(data))
+
+	f, err = openLogFile(name, true, filePerms{gid: -1})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	data, err = os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PublishError is an event that message bus logger failed to publish
+type PublishError struct {
+	Event Event
+	Err   error
+}
+
+// PublishBatchError is returned by message bus loggers in case some events were not published or acknowledged
+type PublishBatchError struct {
+	Failed []PublishError
+	Total  int
+}
+
+func (e *PublishBatchError) Error() string {
+	if len(e.Failed) == 0 {
+		return "publish failed"
+	}
+
+	return fmt.Sprintf("%d of %d events were not published, first error: %s", len(e.Failed), e.Total, e.Failed[0].Err)
+}
+
+// Unwrap returns errors of failed events, so errors.Is & errors.As can find them
+func (e *PublishBatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+
+	return errs
+}
+
+// EventRoute returns tmpl with event tokens replaced: {level} (lower case level name), {source},
+// {type} (number) and {id}. clean makes values safe for the route (e.g. removes subject delimiters),
+// empty values become "none"
+func EventRoute(tmpl string, e Event, clean func(string) string) string {
+	value := func(s string) string {
+		if clean != nil {
+			s = clean(s)
+		}
+		if s == "" {
+			return "none"
+		}
+		return s
+	}
+	r := strings.NewReplacer(
+		"{level}", value(strings.ToLower(e.Level.String())),
+		"{source}", value(e.Source.Text),
+		"{type}", value(strconv.Itoa(int(e.Type))),
+		"{id}", value(e.ID),
+	)
+
+	return r.Replace(tmpl)
+}
+
+// routeChars returns function that replaces characters not accepted by ok with _
+func routeChars(ok func(r rune) bool) func(string) string {
+	return func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if ok(r) {
+				return r
+			}
+			return '_'
+		}, s)
+	}
+}
@@ -4,6 +4,7 @@ type MockLogger struct {
 	LoggedData    Event
 	Format        string
 	LogType       []LogType
+	Err           error
 	wasCalledLog  bool
 	wasCalledType bool
 }
@@ -13,7 +14,7 @@ func (l *MockLogger) Log(e Event, timeFormat string) error {
 	l.Format = timeFormat
 	l.wasCalledLog = true
 
-	return nil
+	return l.Err
 }
 
 func (l *MockLogger) Type() []LogType {
@@ -28,13 +29,13 @@ type MockFile struct {
 func (l *MockFile) Write(b []byte) (n int, err error) {
 	l.Text = append(l.Text, string(b))
 
-	return 0, nil
+	return len(b), nil
 }
 
 func (l *MockFile) WriteString(s string) (n int, err error) {
 	l.Text = append(l.Text, s)
 
-	return 0, nil
+	return len(s), nil
 }
 
 func (l *MockFile) Close() error {
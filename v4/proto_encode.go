@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errProtoMalformed = errors.New("malformed protobuf message")
+
+// protoBuf is a minimal protocol buffers encoder for push APIs (Loki, OTLP).
+// It lets loggers speak protobuf without generated code and its dependencies.
+// Zero values are skipped, as proto3 does.
+type protoBuf struct {
+	b []byte
+}
+
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+func (p *protoBuf) varint(v uint64) {
+	p.b = binary.AppendUvarint(p.b, v)
+}
+
+func (p *protoBuf) tag(field int, wire int) {
+	p.varint(uint64(field)<<3 | uint64(wire))
+}
+
+// uvarint writes unsigned integer field (uint32, uint64, enum, bool)
+func (p *protoBuf) uvarint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	p.tag(field, protoVarint)
+	p.varint(v)
+}
+
+// int64 writes int64 or int32 field
+func (p *protoBuf) int64(field int, v int64) {
+	p.uvarint(field, uint64(v))
+}
+
+func (p *protoBuf) fixed64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	p.tag(field, protoFixed64)
+	p.b = binary.LittleEndian.AppendUint64(p.b, v)
+}
+
+func (p *protoBuf) fixed32(field int, v uint32) {
+	if v == 0 {
+		return
+	}
+	p.tag(field, protoFixed32)
+	p.b = binary.LittleEndian.AppendUint32(p.b, v)
+}
+
+func (p *protoBuf) bytes(field int, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	p.tag(field, protoBytes)
+	p.varint(uint64(len(v)))
+	p.b = append(p.b, v...)
+}
+
+func (p *protoBuf) string(field int, v string) {
+	if v == "" {
+		return
+	}
+	p.tag(field, protoBytes)
+	p.varint(uint64(len(v)))
+	p.b = append(p.b, v...)
+}
+
+// message writes embedded message made by f. Empty messages are written too,
+// because presence of message can matter (e.g. empty repeated element)
+func (p *protoBuf) message(field int, f func(m *protoBuf)) {
+	var m protoBuf
+	f(&m)
+	p.tag(field, protoBytes)
+	p.varint(uint64(len(m.b)))
+	p.b = append(p.b, m.b...)
+}
+
+// protoScan calls f for every field of message b (one level). Length-delimited values are in data,
+// other values are in val. It is enough to read small responses (e.g. OTLP partial success)
+func protoScan(b []byte, f func(field int, wire int, val uint64, data []byte)) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoMalformed
+		}
+		b = b[n:]
+		var val uint64
+		var data []byte
+		switch int(key & 7) {
+		case protoVarint:
+			if val, n = binary.Uvarint(b); n <= 0 {
+				return errProtoMalformed
+			}
+			b = b[n:]
+		case protoFixed64:
+			if len(b) < 8 {
+				return errProtoMalformed
+			}
+			val, b = binary.LittleEndian.Uint64(b), b[8:]
+		case protoFixed32:
+			if len(b) < 4 {
+				return errProtoMalformed
+			}
+			val, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case protoBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return errProtoMalformed
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			return errProtoMalformed
+		}
+		f(int(key>>3), int(key&7), val, data)
+	}
+
+	return nil
+}
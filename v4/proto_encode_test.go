@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// protoField is a decoded protobuf field. val holds varint & fixed values, data holds bytes
+type protoField struct {
+	num  int
+	wire int
+	val  uint64
+	data []byte
+}
+
+// decodeProto decodes one level of protobuf message
+func decodeProto(t *testing.T, b []byte) []protoField {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case protoVarint:
+			f.val, n = binary.Uvarint(b)
+			require.True(t, n > 0)
+			b = b[n:]
+		case protoFixed64:
+			f.val = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case protoFixed32:
+			f.val = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case protoBytes:
+			size, n := binary.Uvarint(b)
+			require.True(t, n > 0)
+			f.data = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			t.Fatalf("unknown wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// protoGet returns fields with number num
+func protoGet(fields []protoField, num int) []protoField {
+	var res []protoField
+	for _, f := range fields {
+		if f.num == num {
+			res = append(res, f)
+		}
+	}
+
+	return res
+}
+
+func TestProtoBuf(t *testing.T) {
+	var p protoBuf
+	p.uvarint(1, 300)
+	p.uvarint(2, 0)
+	p.string(3, "hi")
+	p.fixed64(4, 7)
+	p.fixed32(5, 9)
+	p.int64(6, -1)
+	p.message(7, func(m *protoBuf) { m.string(1, "nested") })
+	p.message(8, func(m *protoBuf) {})
+
+	//Known encoding of field 1 = 300
+	assert.Equal(t, []byte{0x08, 0xac, 0x02}, p.b[:3])
+
+	f := decodeProto(t, p.b)
+	require.Equal(t, 7, len(f))
+	assert.Equal(t, uint64(300), f[0].val)
+	assert.Equal(t, "hi", string(protoGet(f, 3)[0].data))
+	assert.Equal(t, uint64(7), protoGet(f, 4)[0].val)
+	assert.Equal(t, uint64(9), protoGet(f, 5)[0].val)
+	assert.Equal(t, int64(-1), int64(protoGet(f, 6)[0].val))
+	assert.Equal(t, "nested", string(decodeProto(t, protoGet(f, 7)[0].data)[0].data))
+	assert.Equal(t, 0, len(protoGet(f, 8)[0].data))
+}
@@ -0,0 +1,477 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotationOptions determines how RotatingFile changes files and frames records in them
+type RotationOptions struct {
+	//RotateAfter makes file change in case nothing was written into it for this period of time.
+	//Zero means file is not rotated by inactivity.
+	RotateAfter time.Duration
+
+	//Schedule makes file change at specific wall-clock moments (see Hourly, Daily, Weekly).
+	//File is changed by schedule even when no events are logged.
+	Schedule RotationSchedule
+
+	//Location is the time zone for Schedule and file names. Default is time.Local
+	Location *time.Location
+
+	//NameTemplate determines file names (see FileNameFromTemplate), e.g. "{path}-{date}.{ext}".
+	//Default is path-Y_M_D_H_M_S.ext. In case file with the same name exists, records are appended to it.
+	NameTemplate string
+
+	//Lock makes every write take exclusive advisory lock (flock) on the file, so several processes
+	//can share one file without interleaving records. Files are not truncated on start or rotation then:
+	//use NameTemplate with date or {pid} so processes agree on file names. With FlushPolicy the lock is
+	//taken once per flush, which contains whole records only
+	Lock bool
+
+	//FileMode & DirMode are permissions of new log files and their directories. They are set
+	//regardless of umask, e.g. 0640 & 0750 to keep logs private. Zero means 0666 & 0777 limited by umask
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	//Group (name or ID) is set as the owner group of new files and directories. Empty means default
+	Group string
+
+	//Symlink is the path of stable link to current file (e.g. "app.log"), so external tools
+	//can tail it. Link is updated every time file is changed. It must differ from every name
+	//NameTemplate can produce, and existing regular file is never replaced with the link
+	Symlink string
+
+	//Header is written at the beginning of every new file (e.g. CSV head or "[" of JSON array)
+	Header []byte
+
+	//Footer is written at the end of file before it's closed (e.g. "]" of JSON array)
+	Footer []byte
+
+	//Separator is written between records of the same file (e.g. ",\n" for JSON)
+	Separator []byte
+}
+
+// RotatingFile is a log file that is changed to new one according to RotationOptions.
+// It's the core of plaintext, CSV & JSON file loggers and can be used by custom file loggers:
+// logger only needs to format the record and call Write.
+type RotatingFile struct {
+	path     string
+	ext      string
+	opts     RotationOptions
+	flushPol FlushPolicy
+
+	mu        sync.Mutex
+	file      IFile
+	name      string
+	lastWrite time.Time
+	records   int
+	next      time.Time
+	rotateErr error
+	stop      chan struct{}
+
+	now  func() time.Time
+	open func(name string, truncate bool) (IFile, error)
+}
+
+// NewRotatingFile returns RotatingFile that creates files named according to opts.NameTemplate.
+//
+// By passing IFile interface as f you can set the initial object to write logs to. Otherwise path & truncate
+// will be used to create new file. Header is written into initial file only if truncate is true, because
+// otherwise file may already have it.
+func NewRotatingFile(path string, ext string, truncate bool, f IFile, opts RotationOptions) (*RotatingFile, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	rf := &RotatingFile{
+		path: path,
+		ext:  ext,
+		opts: opts,
+		now:  time.Now,
+	}
+
+	perms, err := newFilePerms(opts)
+	if err != nil {
+		return nil, fmt.Errorf("[NewRotatingFile] %w", err)
+	}
+	rf.open = func(name string, truncate bool) (IFile, error) {
+		return openLogFile(name, truncate, perms)
+	}
+	if opts.Symlink != "" {
+		if err := rf.checkSymlink(); err != nil {
+			return nil, fmt.Errorf("[NewRotatingFile] %w", err)
+		}
+	}
+	//Fail early instead of losing events on first write or rotation
+	if f == nil || opts.RotateAfter > 0 || opts.Schedule != nil {
+		dir := filepath.Dir(rf.fileName(rf.now().In(opts.Location)))
+		if err := perms.validateDir(dir); err != nil {
+			return nil, fmt.Errorf("[NewRotatingFile] %w", err)
+		}
+	}
+
+	if err := rf.init(truncate, f); err != nil {
+		return nil, fmt.Errorf("[NewRotatingFile] %w", err)
+	}
+	if opts.Schedule != nil {
+		rf.stop = make(chan struct{})
+		go rf.runSchedule(rf.stop)
+	}
+
+	return rf, nil
+}
+
+func (rf *RotatingFile) init(truncate bool, f IFile) error {
+	now := rf.now().In(rf.opts.Location)
+
+	rf.lastWrite = now
+	if rf.opts.Schedule != nil {
+		rf.next = rf.opts.Schedule.Next(now)
+	}
+	if f == nil {
+		//Shared file may already have records of other processes
+		return rf.startFile(rf.fileName(now), truncate && !rf.opts.Lock)
+	}
+
+	if rf.opts.Lock {
+		lf, err := newLockedFile(f)
+		if err != nil {
+			return err
+		}
+		f = lf
+	}
+	rf.file = f
+	if truncate && len(rf.opts.Header) > 0 {
+		if _, err := rf.file.Write(rf.opts.Header); err != nil {
+			return fmt.Errorf("error writing file header: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkSymlink returns error in case symlink (or its temporary file) can be the name of a log file:
+// updating the link would replace the file with link to itself
+func (rf *RotatingFile) checkSymlink() error {
+	link, err := filepath.Abs(rf.opts.Symlink)
+	if err != nil {
+		return fmt.Errorf("invalid symlink path: %w", err)
+	}
+	names, err := fileNamePattern(rf.opts.NameTemplate, rf.path, rf.ext)
+	if err != nil {
+		return fmt.Errorf("invalid file name: %w", err)
+	}
+	if names.MatchString(link) || names.MatchString(link+".tmp") {
+		return fmt.Errorf("symlink %s can be the name of log file", rf.opts.Symlink)
+	}
+
+	return nil
+}
+
+// fileName returns name of file that is started at t
+func (rf *RotatingFile) fileName(t time.Time) string {
+	if rf.opts.NameTemplate == "" {
+		return logFileName(rf.path, rf.ext, t)
+	}
+
+	return FileNameFromTemplate(rf.opts.NameTemplate, rf.path, rf.ext, t)
+}
+
+// runSchedule changes file by schedule until stop is closed
+func (rf *RotatingFile) runSchedule(stop chan struct{}) {
+	for {
+		rf.mu.Lock()
+		wait := rf.next.Sub(rf.now())
+		rf.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		rf.mu.Lock()
+		//File could be closed while waiting for the lock
+		if rf.stop != stop {
+			rf.mu.Unlock()
+			return
+		}
+		if now := rf.now().In(rf.opts.Location); !now.Before(rf.next) {
+			if err := rf.rotate(now); err != nil {
+				rf.rotateErr = err
+			}
+		}
+		rf.mu.Unlock()
+	}
+}
+
+// Write writes record b into current file, changing the file first in case it's time to.
+// l is the level of event the record was made of, it's used by FlushPolicy.
+func (rf *RotatingFile) Write(b []byte, l Level) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	//Error of rotation by schedule is returned with next record
+	if err := rf.rotateErr; err != nil {
+		rf.rotateErr = nil
+		return fmt.Errorf("[RotatingFile][Write] %w", err)
+	}
+
+	//Make new file in case old one is... old. Time of the last write should be checked
+	//before it's updated with current one
+	now := rf.now().In(rf.opts.Location)
+	if (rf.opts.RotateAfter > 0 && now.Sub(rf.lastWrite) > rf.opts.RotateAfter) ||
+		(rf.opts.Schedule != nil && !now.Before(rf.next)) {
+		if err := rf.rotate(now); err != nil {
+			return fmt.Errorf("[RotatingFile][Write] %w", err)
+		}
+	}
+	rf.lastWrite = now
+
+	//Previous rotation or reopening could fail after the old file was closed
+	if rf.file == nil {
+		if err := rf.startFile(rf.fileName(now), false); err != nil {
+			return fmt.Errorf("[RotatingFile][Write] %w", err)
+		}
+	}
+
+	rec := b
+	if rf.records > 0 && len(rf.opts.Separator) > 0 {
+		rec = make([]byte, 0, len(rf.opts.Separator)+len(b))
+		rec = append(rec, rf.opts.Separator...)
+		rec = append(rec, b...)
+	}
+	if _, err := rf.file.Write(rec); err != nil {
+		return fmt.Errorf("[RotatingFile][Write] error making log entry: %w", err)
+	}
+	rf.records++
+
+	if err := fileWritten(rf.file, l); err != nil {
+		return fmt.Errorf("[RotatingFile][Write] %w", err)
+	}
+
+	return nil
+}
+
+// Rotate closes current file and starts new one right now
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if err := rf.rotate(rf.now().In(rf.opts.Location)); err != nil {
+		return fmt.Errorf("[RotatingFile][Rotate] %w", err)
+	}
+
+	return nil
+}
+
+func (rf *RotatingFile) rotate(now time.Time) error {
+	if rf.opts.Schedule != nil {
+		rf.next = rf.opts.Schedule.Next(now)
+	}
+	if err := rf.closeFile(); err != nil {
+		return err
+	}
+	//Old file is closed: in case new one can not be opened, it's tried again on next write
+	rf.file = nil
+
+	//Other processes may be writing into the same file
+	return rf.startFile(rf.fileName(now), rf.opts.NameTemplate == "" && !rf.opts.Lock)
+}
+
+// Reopen closes current file and opens the file with the same name again. It's meant for external
+// rotation tools like logrotate: after the file was moved away, new one is created at the same path.
+// Use NameTemplate without time tokens (e.g. "{path}.{ext}") to have stable file name.
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	name := rf.name
+	if name == "" {
+		name = rf.fileName(rf.now().In(rf.opts.Location))
+	}
+	if err := rf.closeFile(); err != nil {
+		return fmt.Errorf("[RotatingFile][Reopen] %w", err)
+	}
+	rf.file = nil
+	if err := rf.startFile(name, false); err != nil {
+		return fmt.Errorf("[RotatingFile][Reopen] %w", err)
+	}
+
+	return nil
+}
+
+// startFile opens new current file and writes header into it in case it's empty
+func (rf *RotatingFile) startFile(name string, truncate bool) error {
+	if err := rf.openFile(name, truncate); err != nil {
+		return err
+	}
+	f, err := wrapFile(rf.file, rf.flushPol)
+	if err != nil {
+		return err
+	}
+	raw := rf.file
+	rf.file = f
+	rf.records = 0
+
+	//File named by template may exist and already have the header
+	if len(rf.opts.Header) == 0 {
+		return nil
+	}
+	if lf, ok := raw.(*lockedFile); ok {
+		if err := lf.writeIfEmpty(rf.opts.Header); err != nil {
+			return fmt.Errorf("error writing file header: %w", err)
+		}
+	} else if isEmptyFile(raw) {
+		if _, err := rf.file.Write(rf.opts.Header); err != nil {
+			return fmt.Errorf("error writing file header: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// openFile opens file at name and points symlink to it
+func (rf *RotatingFile) openFile(name string, truncate bool) error {
+	f, err := rf.open(name, truncate)
+	if err != nil {
+		return err
+	}
+	if rf.opts.Lock {
+		lf, err := newLockedFile(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		f = lf
+	}
+	rf.file = f
+	rf.name = name
+
+	if rf.opts.Symlink != "" {
+		if err := updateSymlink(rf.opts.Symlink, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSymlink atomically points link to target. Relative path is used when possible,
+// so link stays valid in case the whole directory is moved
+func updateSymlink(link string, target string) error {
+	dest := target
+	if abs, err := filepath.Abs(target); err == nil {
+		dest = abs
+		if absLink, err := filepath.Abs(link); err == nil {
+			if rel, err := filepath.Rel(filepath.Dir(absLink), abs); err == nil {
+				dest = rel
+			}
+		}
+	}
+
+	//Link path may be taken by a real file (e.g. log of older version): it should not be lost
+	if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("error making symlink: %s exists and is not a symlink", link)
+	}
+
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(dest, tmp); err != nil {
+		return fmt.Errorf("error making symlink: %w", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error making symlink: %w", err)
+	}
+
+	return nil
+}
+
+// closeFile writes footer and closes current file. It does nothing in case there is no open file
+func (rf *RotatingFile) closeFile() error {
+	if rf.file == nil {
+		return nil
+	}
+	if len(rf.opts.Footer) > 0 {
+		if _, err := rf.file.Write(rf.opts.Footer); err != nil {
+			return fmt.Errorf("error writing file footer: %w", err)
+		}
+	}
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	return nil
+}
+
+// SetFlushPolicy makes file buffer records in memory and write them according to p.
+// Zero policy turns buffering off. Buffer is flushed before file is rotated.
+func (rf *RotatingFile) SetFlushPolicy(p FlushPolicy) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	//Without open file policy is applied when the file is opened
+	if rf.file != nil {
+		f, err := wrapFile(rf.file, p)
+		if err != nil {
+			return fmt.Errorf("[RotatingFile][SetFlushPolicy] %w", err)
+		}
+		rf.file = f
+	}
+	rf.flushPol = p
+
+	return nil
+}
+
+// Flush writes buffered records to the file
+func (rf *RotatingFile) Flush() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	if err := flushFile(rf.file); err != nil {
+		return fmt.Errorf("[RotatingFile][Flush] %w", err)
+	}
+
+	return nil
+}
+
+// Close stops rotation by schedule, writes footer and closes current file.
+// RotatingFile should not be used after Close.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.stop != nil {
+		close(rf.stop)
+		rf.stop = nil
+	}
+	if err := rf.closeFile(); err != nil {
+		return fmt.Errorf("[RotatingFile][Close] %w", err)
+	}
+
+	return nil
+}
+
+// LastWrite returns last time a record was written
+func (rf *RotatingFile) LastWrite() time.Time {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.lastWrite
+}
+
+// SetLastWrite sets last time a record was written
+func (rf *RotatingFile) SetLastWrite(t time.Time) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	rf.lastWrite = t
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRotatingFile returns RotatingFile with fake clock that opens MockFiles
+func newTestRotatingFile(t *testing.T, opts RotationOptions) (*RotatingFile, *time.Time, *[]*MockFile) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	first := &MockFile{}
+	files := []*MockFile{first}
+
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	rf, err := NewRotatingFile("some", "log", true, first, opts)
+	require.NoError(t, err)
+	rf.now = func() time.Time { return now }
+	rf.lastWrite = now
+	rf.open = func(name string, truncate bool) (IFile, error) {
+		f := &MockFile{}
+		files = append(files, f)
+		return f, nil
+	}
+
+	return rf, &now, &files
+}
+
+// File should be changed only after RotateAfter without writes
+func TestRotatingFileRotateAfter(t *testing.T) {
+	rf, now, files := newTestRotatingFile(t, RotationOptions{RotateAfter: time.Minute})
+
+	require.NoError(t, rf.Write([]byte("event1\n"), INFO))
+	*now = now.Add(50 * time.Second)
+	require.NoError(t, rf.Write([]byte("event2\n"), INFO))
+	*now = now.Add(50 * time.Second)
+	require.NoError(t, rf.Write([]byte("event3\n"), INFO))
+	assert.Equal(t, 1, len(*files))
+
+	*now = now.Add(61 * time.Second)
+	require.NoError(t, rf.Write([]byte("event4\n"), INFO))
+	require.Equal(t, 2, len(*files))
+	assert.Equal(t, []string{"event1\n", "event2\n", "event3\n"}, (*files)[0].Text)
+	assert.Equal(t, []string{"event4\n"}, (*files)[1].Text)
+	assert.Equal(t, *now, rf.LastWrite())
+}
+
+// Failed rotation should not leave closed file as current one: it should be opened on next write
+func TestRotatingFileRotateFailure(t *testing.T) {
+	rf, _, files := newTestRotatingFile(t, RotationOptions{})
+	open := rf.open
+	rf.open = func(name string, truncate bool) (IFile, error) {
+		return nil, errors.New("disk is full")
+	}
+
+	require.NoError(t, rf.Write([]byte("event1\n"), INFO))
+	assert.Error(t, rf.Rotate())
+	assert.Error(t, rf.Write([]byte("event2\n"), INFO))
+	require.NoError(t, rf.Flush())
+
+	rf.open = open
+	require.NoError(t, rf.Write([]byte("event3\n"), INFO))
+	require.Equal(t, 2, len(*files))
+	assert.Equal(t, []string{"event1\n"}, (*files)[0].Text)
+	assert.Equal(t, []string{"event3\n"}, (*files)[1].Text)
+	require.NoError(t, rf.Close())
+}
+
+// Header, footer & separator should frame records of every file
+func TestRotatingFileFraming(t *testing.T) {
+	rf, _, files := newTestRotatingFile(t, RotationOptions{
+		Header:    []byte("["),
+		Footer:    []byte("]"),
+		Separator: []byte(","),
+	})
+
+	require.NoError(t, rf.Write([]byte("1"), INFO))
+	require.NoError(t, rf.Write([]byte("2"), INFO))
+	require.NoError(t, rf.Rotate())
+	require.NoError(t, rf.Write([]byte("3"), INFO))
+	require.NoError(t, rf.Close())
+
+	assert.Equal(t, []string{"[", "1", ",2", "]"}, (*files)[0].Text)
+	assert.Equal(t, []string{"[", "3", "]"}, (*files)[1].Text)
+}
+
+// Zero RotateAfter should never change the file
+func TestRotatingFileNoRotation(t *testing.T) {
+	rf, now, files := newTestRotatingFile(t, RotationOptions{})
+
+	require.NoError(t, rf.Write([]byte("event1\n"), INFO))
+	*now = now.Add(24 * time.Hour)
+	require.NoError(t, rf.Write([]byte("event2\n"), INFO))
+	assert.Equal(t, 1, len(*files))
+}
+
+// CSV head should be written into every new file and JSON records separated
+func TestRotatingFileLoggers(t *testing.T) {
+	csv, err := NewCSVtext("some", true, 1, &MockFile{}, Any)
+	require.NoError(t, err)
+	js, err := NewJSONtext("some", true, 1, &MockFile{}, Any)
+	require.NoError(t, err)
+
+	var csvFiles, jsFiles []*MockFile
+	csv.file.open = func(name string, truncate bool) (IFile, error) {
+		f := &MockFile{}
+		csvFiles = append(csvFiles, f)
+		return f, nil
+	}
+	js.file.open = func(name string, truncate bool) (IFile, error) {
+		f := &MockFile{}
+		jsFiles = append(jsFiles, f)
+		return f, nil
+	}
+
+	e := Info("event1").FixTime()
+	p := New(false, "", make(chan error), false, csv, js)
+	p.Log(e)
+	p.Log(e)
+
+	csv.SetLastLog(time.Now().Add(-2 * time.Minute))
+	js.SetLastLog(time.Now().Add(-2 * time.Minute))
+	p.Log(e)
+
+	require.Equal(t, 1, len(csvFiles))
+	assert.Equal(t, []string{CSVHead, FormatCSV(e, time.UnixDate)}, csvFiles[0].Text)
+
+	result, err := FormatJSON(e, time.UnixDate)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(jsFiles))
+	assert.Equal(t, []string{string(result)}, jsFiles[0].Text)
+}
+
+func TestFileNameFromTemplate(t *testing.T) {
+	now := time.Date(2026, 10, 8, 9, 5, 3, 0, time.UTC)
+
+	assert.Equal(t, "logs/app-2026-10-08.log", FileNameFromTemplate("{path}-{date}.{ext}", "logs/app", "log", now))
+	assert.Equal(t, "app/2026/10/08/09-05-03.csv", FileNameFromTemplate("{path}/{YYYY}/{MM}/{DD}/{time}.{ext}", "app", "csv", now))
+	assert.Equal(t, "app_09h05m03s.json", FileNameFromTemplate("{path}_{hh}h{mm}m{ss}s.{ext}", "app", "json", now))
+	assert.Equal(t, "app-1_2_3_4_5_6.log", logFileName("app", "log", time.Date(1, 2, 3, 4, 5, 6, 0, time.UTC)))
+}
+
+// File should be changed on write in case schedule moment has passed
+func TestRotatingFileSchedule(t *testing.T) {
+	rf, now, files := newTestRotatingFile(t, RotationOptions{NameTemplate: "{path}-{date}.{ext}"})
+	//Schedule is set manually to avoid background rotation
+	rf.opts.Schedule = Daily()
+	rf.next = Daily().Next(*now)
+
+	var names []string
+	rf.open = func(name string, truncate bool) (IFile, error) {
+		assert.False(t, truncate)
+		names = append(names, name)
+		f := &MockFile{}
+		*files = append(*files, f)
+		return f, nil
+	}
+
+	require.NoError(t, rf.Write([]byte("event1\n"), INFO))
+	*now = now.Add(11 * time.Hour)
+	require.NoError(t, rf.Write([]byte("event2\n"), INFO))
+	assert.Equal(t, 1, len(*files))
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, rf.Write([]byte("event3\n"), INFO))
+	require.Equal(t, 2, len(*files))
+	assert.Equal(t, []string{"some-2026-10-19.log"}, names)
+	assert.Equal(t, []string{"event1\n", "event2\n"}, (*files)[0].Text)
+	assert.Equal(t, []string{"event3\n"}, (*files)[1].Text)
+	assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), rf.next)
+}
+
+// everyTick rotates files every d
+type everyTick time.Duration
+
+func (d everyTick) Next(t time.Time) time.Time { return t.Add(time.Duration(d)) }
+
+// File should be changed by schedule even if nothing is logged
+func TestRotatingFileScheduleBackground(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewCSVRotating(filepath.Join(dir, "app"), true, RotationOptions{
+		Schedule:     everyTick(50 * time.Millisecond),
+		NameTemplate: "{path}-{time}-{ss}{MM}.{ext}",
+	}, Any)
+	require.NoError(t, err)
+
+	require.NoError(t, l.Log(Info("event1").FixTime(), time.UnixDate))
+	assert.Eventually(t, func() bool {
+		m, _ := filepath.Glob(filepath.Join(dir, "app-*.csv"))
+		return len(m) > 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.NoError(t, l.Close())
+
+	//Every new file should have CSV head
+	m, err := filepath.Glob(filepath.Join(dir, "app-*.csv"))
+	require.NoError(t, err)
+	for _, name := range m {
+		b, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(b), CSVHead), name)
+	}
+}
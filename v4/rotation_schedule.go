@@ -0,0 +1,58 @@
+package logger
+
+import "time"
+
+// RotationSchedule determines wall-clock moments when RotatingFile changes the file
+type RotationSchedule interface {
+	//Next returns the first moment of rotation after t. Result should be in t's location
+	Next(t time.Time) time.Time
+}
+
+// hourlyRotation changes file at the beginning of every hour
+type hourlyRotation struct{}
+
+func (hourlyRotation) Next(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+}
+
+// dailyRotation changes file every day at hour:min
+type dailyRotation struct {
+	hour int
+	min  int
+}
+
+func (d dailyRotation) Next(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), d.hour, d.min, 0, 0, t.Location())
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, d.hour, d.min, 0, 0, t.Location())
+	}
+
+	return next
+}
+
+// weeklyRotation changes file every week at midnight of day
+type weeklyRotation struct {
+	day time.Weekday
+}
+
+func (w weeklyRotation) Next(t time.Time) time.Time {
+	days := (int(w.day) - int(t.Weekday()) + 7) % 7
+	next := time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, 0, t.Location())
+	if !next.After(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+days+7, 0, 0, 0, 0, t.Location())
+	}
+
+	return next
+}
+
+// Hourly returns schedule that changes file at the beginning of every hour
+func Hourly() RotationSchedule { return hourlyRotation{} }
+
+// Daily returns schedule that changes file every day at midnight
+func Daily() RotationSchedule { return dailyRotation{} }
+
+// DailyAt returns schedule that changes file every day at hour:min
+func DailyAt(hour, min int) RotationSchedule { return dailyRotation{hour: hour, min: min} }
+
+// Weekly returns schedule that changes file every week at midnight of day
+func Weekly(day time.Weekday) RotationSchedule { return weeklyRotation{day: day} }
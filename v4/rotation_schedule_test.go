@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationScheduleNext(t *testing.T) {
+	// Sunday, 18 Oct 2026
+	now := time.Date(2026, 10, 18, 12, 30, 15, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 18, 13, 0, 0, 0, time.UTC), Hourly().Next(now))
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Daily().Next(now))
+	assert.Equal(t, time.Date(2026, 10, 18, 18, 45, 0, 0, time.UTC), DailyAt(18, 45).Next(now))
+	assert.Equal(t, time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC), DailyAt(6, 0).Next(now))
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Weekly(time.Monday).Next(now))
+	assert.Equal(t, time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC), Weekly(time.Sunday).Next(now))
+
+	//Exact moment of rotation should give the next one
+	midnight := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), Daily().Next(midnight))
+	assert.Equal(t, time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC), Hourly().Next(midnight))
+	assert.Equal(t, time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC), Weekly(time.Monday).Next(midnight))
+}
+
+// Schedule should follow wall clock of the location, including DST changes
+func TestRotationScheduleLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+
+	//DST ends on 25 Oct 2026: the day is 25 hours long
+	now := time.Date(2026, 10, 25, 0, 0, 0, 0, loc)
+	next := Daily().Next(now)
+	assert.Equal(t, time.Date(2026, 10, 26, 0, 0, 0, 0, loc), next)
+	assert.Equal(t, 25*time.Hour, next.Sub(now))
+
+	//Midnight in Berlin is not midnight in UTC
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, loc), Daily().Next(time.Date(2026, 10, 18, 23, 0, 0, 0, loc)))
+}
@@ -0,0 +1,470 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	//ErrSpoolFull is returned by Spool.Append in case event does not fit into spool size limit
+	ErrSpoolFull = errors.New("spool is full")
+
+	//ErrSpoolCorrupted is returned in case spool segment has broken record. Segment is renamed to *.corrupt
+	//by Replay so it could be inspected later, all events after the broken record are lost
+	ErrSpoolCorrupted = errors.New("spool segment is corrupted")
+)
+
+const (
+	spoolSegmentExt  = ".seg"
+	spoolCorruptExt  = ".corrupt"
+	spoolCursorFile  = "cursor"
+	spoolHeaderSize  = 8
+	spoolMaxRecord   = 64 << 20
+	spoolDefaultSize = 16 << 20
+	spoolReplayChunk = 100
+)
+
+// errSpoolChunkRead stops reading segment once replay chunk is full
+var errSpoolChunkRead = errors.New("spool chunk is read")
+
+// spoolRecord is the spooled event with time format it should be logged with
+type spoolRecord struct {
+	Event      Event  `json:"event"`
+	TimeFormat string `json:"time_format"`
+}
+
+type spoolSegment struct {
+	seq  uint64
+	size int64
+}
+
+// SpoolStats represents current state of Spool
+type SpoolStats struct {
+	//Segments is the number of segment files waiting to be replayed
+	Segments int
+	//Bytes is the size of records waiting to be replayed
+	Bytes int64
+	//Corrupted is the number of segments that were found broken since Spool was opened
+	Corrupted int
+}
+
+// Spool is a durable on-disk queue of events that could not be delivered to a logger.
+// Events are appended to segment files in the spool directory and replayed in the same order.
+//
+// Every record is: 4 bytes of payload length, 4 bytes of payload CRC32 (IEEE), JSON payload.
+// Replay position is stored in cursor file, so events are delivered at least once: in case app
+// stops in the middle of replay, some events may be replayed again after restart.
+type Spool struct {
+	dir         string
+	segmentSize int64
+	maxSize     int64
+
+	//replayMu serializes replays, so events are not delivered twice
+	replayMu sync.Mutex
+
+	mu        sync.Mutex
+	segs      []spoolSegment
+	w         *os.File
+	cursorSeq uint64
+	cursorOff int64
+	corrupted int
+}
+
+// NewSpool opens spool in dir (creating it if needed) and loads segments left from previous runs.
+//
+// segmentSize is the size of segment file after which new segment is started (16MB if <= 0).
+// maxSize limits total size of spool, zero means no limit.
+func NewSpool(dir string, segmentSize int64, maxSize int64) (*Spool, error) {
+	if segmentSize <= 0 {
+		segmentSize = spoolDefaultSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("[NewSpool] error making spool dir: %w", err)
+	}
+
+	s := &Spool{dir: dir, segmentSize: segmentSize, maxSize: maxSize}
+
+	if err := s.loadCursor(); err != nil {
+		return nil, fmt.Errorf("[NewSpool] %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("[NewSpool] error reading spool dir: %w", err)
+	}
+	for _, en := range entries {
+		name := en.Name()
+		if en.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := en.Info()
+		if err != nil {
+			return nil, fmt.Errorf("[NewSpool] %w", err)
+		}
+		//Segments before cursor were fully replayed, but not deleted
+		if seq < s.cursorSeq {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		s.segs = append(s.segs, spoolSegment{seq: seq, size: info.Size()})
+	}
+	sort.Slice(s.segs, func(i, j int) bool { return s.segs[i].seq < s.segs[j].seq })
+
+	return s, nil
+}
+
+func (s *Spool) segPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentExt))
+}
+
+func (s *Spool) loadCursor() error {
+	b, err := os.ReadFile(filepath.Join(s.dir, spoolCursorFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading spool cursor: %w", err)
+	}
+	if _, err := fmt.Sscanf(string(b), "%d %d", &s.cursorSeq, &s.cursorOff); err != nil {
+		return fmt.Errorf("error parsing spool cursor: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Spool) saveCursor() error {
+	tmp := filepath.Join(s.dir, spoolCursorFile+".tmp")
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", s.cursorSeq, s.cursorOff)), 0600); err != nil {
+		return fmt.Errorf("error writing spool cursor: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, spoolCursorFile)); err != nil {
+		return fmt.Errorf("error writing spool cursor: %w", err)
+	}
+
+	return nil
+}
+
+// Append writes event to the end of spool. Data is synced to disk before Append returns.
+func (s *Spool) Append(e Event, timeFormat string) error {
+	payload, err := json.Marshal(spoolRecord{Event: e, TimeFormat: timeFormat})
+	if err != nil {
+		return fmt.Errorf("[Spool][Append] error encoding event: %w", err)
+	}
+	rec := make([]byte, spoolHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(rec[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(payload))
+	copy(rec[spoolHeaderSize:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size()+int64(len(rec)) > s.maxSize {
+		return fmt.Errorf("[Spool][Append] %w", ErrSpoolFull)
+	}
+
+	last := len(s.segs) - 1
+	if s.w == nil || (s.segs[last].size > 0 && s.segs[last].size+int64(len(rec)) > s.segmentSize) {
+		if err := s.roll(); err != nil {
+			return fmt.Errorf("[Spool][Append] %w", err)
+		}
+		last = len(s.segs) - 1
+	}
+
+	if _, err := s.w.Write(rec); err != nil {
+		return fmt.Errorf("[Spool][Append] error writing record: %w", err)
+	}
+	if err := s.w.Sync(); err != nil {
+		return fmt.Errorf("[Spool][Append] error syncing segment: %w", err)
+	}
+	s.segs[last].size += int64(len(rec))
+
+	return nil
+}
+
+// roll closes current segment and starts new one
+func (s *Spool) roll() error {
+	if s.w != nil {
+		s.w.Close()
+		s.w = nil
+	}
+
+	seq := s.cursorSeq
+	if len(s.segs) > 0 {
+		seq = s.segs[len(s.segs)-1].seq + 1
+	}
+	f, err := os.OpenFile(s.segPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("error making spool segment: %w", err)
+	}
+	s.w = f
+	s.segs = append(s.segs, spoolSegment{seq: seq})
+
+	return nil
+}
+
+// size returns number of bytes waiting to be replayed
+func (s *Spool) size() int64 {
+	var total int64
+	for _, seg := range s.segs {
+		total += seg.size
+		if seg.seq == s.cursorSeq {
+			total -= s.cursorOff
+		}
+	}
+
+	return total
+}
+
+// Empty returns true in case there is nothing to replay
+func (s *Spool) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size() == 0
+}
+
+// Stats returns current spool state
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return SpoolStats{Segments: len(s.segs), Bytes: s.size(), Corrupted: s.corrupted}
+}
+
+// Replay passes spooled events to fn in the order they were appended and removes them from spool.
+// It stops at first error returned by fn: that event stays in spool and will be the first one next time.
+// Logger's Log method can be used as fn directly.
+//
+// Events are read in chunks and fn is called without holding spool lock, so Append does not wait
+// for a slow logger. Replays are serialized with each other.
+//
+// Broken segments are renamed to *.corrupt and skipped, ErrSpoolCorrupted is returned
+// after all other events are replayed.
+func (s *Spool) Replay(fn func(e Event, timeFormat string) error) (int, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	var replayed int
+	var corrErr error
+	for {
+		s.mu.Lock()
+		if len(s.segs) == 0 {
+			s.mu.Unlock()
+			return replayed, corrErr
+		}
+		seg := s.segs[0]
+		var off int64
+		if seg.seq == s.cursorSeq {
+			off = s.cursorOff
+		}
+		recs, ends, readErr := s.readChunk(seg, off, spoolReplayChunk)
+		s.mu.Unlock()
+
+		var err error
+		for i, rec := range recs {
+			if err = fn(rec.Event, rec.TimeFormat); err != nil {
+				break
+			}
+			replayed++
+			off = ends[i]
+		}
+
+		s.mu.Lock()
+		err = s.advance(seg, off, err, readErr)
+		s.mu.Unlock()
+		if errors.Is(err, ErrSpoolCorrupted) {
+			if corrErr == nil {
+				corrErr = fmt.Errorf("[Spool][Replay] %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return replayed, fmt.Errorf("[Spool][Replay] %w", err)
+		}
+	}
+}
+
+// readChunk reads up to max records of seg starting from off. ends are offsets of records following
+// the returned ones. Records read before spool data turned out to be broken are returned with the error.
+func (s *Spool) readChunk(seg spoolSegment, off int64, max int) ([]spoolRecord, []int64, error) {
+	var recs []spoolRecord
+	var ends []int64
+	err := s.readSegment(seg, off, func(rec spoolRecord, next int64) error {
+		recs = append(recs, rec)
+		ends = append(ends, next)
+		if len(recs) == max {
+			return errSpoolChunkRead
+		}
+
+		return nil
+	})
+	if errors.Is(err, errSpoolChunkRead) {
+		err = nil
+	}
+
+	return recs, ends, err
+}
+
+// advance moves replay position of first segment seg to off after a chunk was delivered.
+// fnErr is the error of delivery, readErr is the error of reading the chunk. ErrSpoolCorrupted is
+// returned in case the segment was moved aside, other errors mean replay should stop.
+func (s *Spool) advance(seg spoolSegment, off int64, fnErr error, readErr error) error {
+	s.cursorSeq, s.cursorOff = seg.seq, off
+	if fnErr == nil && errors.Is(readErr, ErrSpoolCorrupted) {
+		if err := s.quarantine(seg); err != nil {
+			return err
+		}
+		return readErr
+	}
+	if fnErr != nil || readErr != nil {
+		if err := s.saveCursor(); err != nil {
+			return err
+		}
+		if fnErr != nil {
+			return fnErr
+		}
+		return readErr
+	}
+
+	//Segment could have grown while chunk was delivered
+	if off < s.segs[0].size {
+		return s.saveCursor()
+	}
+
+	return s.dropFirst()
+}
+
+// readSegment calls fn for every record of seg starting from off. next is the offset of record after current one.
+func (s *Spool) readSegment(seg spoolSegment, off int64, fn func(rec spoolRecord, next int64) error) error {
+	f, err := os.Open(s.segPath(seg.seq))
+	if err != nil {
+		return fmt.Errorf("error opening spool segment: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading spool segment: %w", err)
+	}
+
+	head := make([]byte, spoolHeaderSize)
+	for {
+		_, err := io.ReadFull(f, head)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %d at offset %d: truncated header", ErrSpoolCorrupted, seg.seq, off)
+		}
+
+		size := binary.BigEndian.Uint32(head[0:4])
+		if size > spoolMaxRecord {
+			return fmt.Errorf("%w: %d at offset %d: bad record size %d", ErrSpoolCorrupted, seg.seq, off, size)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(f, payload); err != nil {
+			return fmt.Errorf("%w: %d at offset %d: truncated record", ErrSpoolCorrupted, seg.seq, off)
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(head[4:8]) {
+			return fmt.Errorf("%w: %d at offset %d: checksum mismatch", ErrSpoolCorrupted, seg.seq, off)
+		}
+
+		var rec spoolRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return fmt.Errorf("%w: %d at offset %d: %v", ErrSpoolCorrupted, seg.seq, off, err)
+		}
+
+		next := off + spoolHeaderSize + int64(size)
+		if err := fn(rec, next); err != nil {
+			return err
+		}
+		off = next
+	}
+}
+
+// dropFirst removes first segment after it was fully replayed
+func (s *Spool) dropFirst() error {
+	seg := s.segs[0]
+	if len(s.segs) == 1 && s.w != nil {
+		s.w.Close()
+		s.w = nil
+	}
+	s.segs = s.segs[1:]
+	s.cursorSeq, s.cursorOff = seg.seq+1, 0
+	if err := s.saveCursor(); err != nil {
+		return err
+	}
+	if err := os.Remove(s.segPath(seg.seq)); err != nil {
+		return fmt.Errorf("error removing spool segment: %w", err)
+	}
+
+	return nil
+}
+
+// quarantine renames broken first segment to *.corrupt and removes it from the queue
+func (s *Spool) quarantine(seg spoolSegment) error {
+	if len(s.segs) == 1 && s.w != nil {
+		s.w.Close()
+		s.w = nil
+	}
+	s.segs = s.segs[1:]
+	s.corrupted++
+	s.cursorSeq, s.cursorOff = seg.seq+1, 0
+	if err := s.saveCursor(); err != nil {
+		return err
+	}
+	if err := os.Rename(s.segPath(seg.seq), strings.TrimSuffix(s.segPath(seg.seq), spoolSegmentExt)+spoolCorruptExt); err != nil {
+		return fmt.Errorf("error moving corrupted spool segment: %w", err)
+	}
+
+	return nil
+}
+
+// Inspect passes spooled events to fn in order without removing them from spool.
+// It stops at first error (returned by fn or found in spool data).
+func (s *Spool) Inspect(fn func(e Event, timeFormat string) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, seg := range s.segs {
+		var off int64
+		if seg.seq == s.cursorSeq {
+			off = s.cursorOff
+		}
+		err := s.readSegment(seg, off, func(rec spoolRecord, next int64) error {
+			return fn(rec.Event, rec.TimeFormat)
+		})
+		if err != nil {
+			return fmt.Errorf("[Spool][Inspect] %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes current segment file. Spool should not be used after Close.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.w == nil {
+		return nil
+	}
+	err := s.w.Close()
+	s.w = nil
+
+	return err
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spoolTexts(t *testing.T, s *Spool) []string {
+	var texts []string
+	require.NoError(t, s.Inspect(func(e Event, timeFormat string) error {
+		texts = append(texts, e.Text)
+		return nil
+	}))
+
+	return texts
+}
+
+// Events should be replayed in order and removed from spool
+func TestSpoolAppendReplay(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 100, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, true, s.Empty())
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Append(Info(fmt.Sprintf("event%d", i)).Src(EvsMain), time.RFC3339))
+	}
+	assert.Equal(t, false, s.Empty())
+	assert.Equal(t, true, s.Stats().Segments > 1)
+	assert.Equal(t, []string{"event0", "event1", "event2", "event3", "event4"}, spoolTexts(t, s))
+
+	var replayed []Event
+	n, err := s.Replay(func(e Event, timeFormat string) error {
+		assert.Equal(t, time.RFC3339, timeFormat)
+		replayed = append(replayed, e)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "event4", replayed[4].Text)
+	assert.Equal(t, EvsMain, replayed[4].Source)
+	assert.Equal(t, INFO, replayed[4].Level)
+	assert.Equal(t, true, s.Empty())
+	assert.Equal(t, 0, s.Stats().Segments)
+}
+
+// Failed replay should keep position, also after spool is reopened
+func TestSpoolReplayStop(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, s.Append(Info(fmt.Sprintf("event%d", i)), ""))
+	}
+
+	n, err := s.Replay(func(e Event, timeFormat string) error {
+		if e.Text == "event2" {
+			return fmt.Errorf("logger is down")
+		}
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+	require.NoError(t, s.Close())
+
+	s, err = NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, []string{"event2", "event3"}, spoolTexts(t, s))
+
+	require.NoError(t, s.Append(Info("event4"), ""))
+	assert.Equal(t, []string{"event2", "event3", "event4"}, spoolTexts(t, s))
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 200)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var err2 error
+	for i := 0; i < 10 && err2 == nil; i++ {
+		err2 = s.Append(Info("event"), "")
+	}
+	assert.ErrorIs(t, err2, ErrSpoolFull)
+	assert.Equal(t, true, s.Stats().Bytes <= 200)
+}
+
+// Broken segment should be moved aside and other segments replayed
+func TestSpoolCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 50, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Append(Info("event0"), ""))
+	require.NoError(t, s.Append(Info("event1"), ""))
+	require.NoError(t, s.Close())
+
+	//Damage payload of the first segment
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", 0, spoolSegmentExt))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)-2] ^= 0xff
+	require.NoError(t, os.WriteFile(path, b, 0600))
+
+	s, err = NewSpool(dir, 50, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.ErrorIs(t, s.Inspect(func(e Event, timeFormat string) error { return nil }), ErrSpoolCorrupted)
+
+	var replayed []string
+	_, err = s.Replay(func(e Event, timeFormat string) error {
+		replayed = append(replayed, e.Text)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrSpoolCorrupted)
+	assert.Equal(t, []string{"event1"}, replayed)
+	assert.Equal(t, 1, s.Stats().Corrupted)
+	assert.FileExists(t, filepath.Join(dir, fmt.Sprintf("%020d%s", 0, spoolCorruptExt)))
+}
+
+// Events should be replayed in chunks without blocking Append, events appended meanwhile should be replayed too
+func TestSpoolReplayAppend(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var want []string
+	for i := 0; i < spoolReplayChunk+50; i++ {
+		want = append(want, fmt.Sprintf("event%d", i))
+		require.NoError(t, s.Append(Info(want[i]), ""))
+	}
+	want = append(want, "late")
+
+	var replayed []string
+	n, err := s.Replay(func(e Event, timeFormat string) error {
+		if len(replayed) == 0 {
+			done := make(chan error)
+			go func() { done <- s.Append(Info("late"), "") }()
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("Append is blocked by Replay")
+			}
+		}
+		replayed = append(replayed, e.Text)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(want), n)
+	assert.Equal(t, want, replayed)
+	assert.Equal(t, true, s.Empty())
+}